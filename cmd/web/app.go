@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"html/template"
 	"log/slog"
 	"net/http"
@@ -10,16 +11,24 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/brandondunbar/personal-site/internal/assets"
 	"github.com/brandondunbar/personal-site/internal/blog"
 	"github.com/brandondunbar/personal-site/internal/config"
+	"github.com/brandondunbar/personal-site/internal/images"
+	"github.com/brandondunbar/personal-site/internal/preview"
 )
 
 type App struct {
 	tpls     *template.Template
 	staticFS http.FileSystem
 	cfg      config.Config
+	rt       config.Runtime
 	log      *slog.Logger
 	blog     blog.Store
+	preview  *preview.Signer   // nil when PREVIEW_SECRET is unset
+	images   *images.Processor // nil: no resized variants to serve
+	assets   *assets.Manifest  // nil: static files keep their plain names
+	static   bool              // rendering for Build: no server-only pages such as search
 }
 
 type TemplateData struct {
@@ -28,24 +37,14 @@ type TemplateData struct {
 	Title string
 }
 
-func NewApp() (*App, error) {
-	tpls, err := template.ParseFiles(
-		templatePath("web/templates/icons.html.tmpl"),
-		templatePath("web/templates/base.html.tmpl"),
-		templatePath("web/templates/home.html.tmpl"),
-		templatePath("web/templates/blog_index.html.tmpl"),
-		templatePath("web/templates/blog_post.html.tmpl"),
-		templatePath("web/templates/404.html.tmpl"), 
-		templatePath("web/templates/500.html.tmpl"),
-		templatePath("web/templates/partials/tri_anim.html.tmpl"),
-		templatePath("web/templates/partials/hex_anim.html.tmpl"),
-		templatePath("web/templates/partials/traces.html.tmpl"),
-		templatePath("web/templates/partials/circle.html.tmpl"),
-		templatePath("web/templates/partials/_project-cards.html.tmpl"),
-		templatePath("web/templates/partials/_bookshelf.html.tmpl"),
-		templatePath("web/templates/partials/about.html.tmpl"),
-		templatePath("web/templates/partials/footer.html.tmpl"),
-	)
+func NewApp(rt config.Runtime) (*App, error) {
+	// Fingerprinted static files; see internal/assets.
+	manifest, err := assets.New(os.DirFS(templatePath("web/static")), "/static/")
+	if err != nil {
+		return nil, err
+	}
+
+	tpls, err := loadTemplates(manifest)
 	if err != nil {
 		return nil, err
 	}
@@ -55,26 +54,105 @@ func NewApp() (*App, error) {
 		return nil, err
 	}
 
+	// Resized variants of project and post images.
+	imgs, err := newImages()
+	if err != nil {
+		return nil, err
+	}
+	if err := projectImages(imgs, &cfg.Work); err != nil {
+		return nil, err
+	}
+
 	// Blog store (filesystem-backed for now)
 	dir := os.Getenv("BLOG_DIR")
 	if dir == "" {
 		dir = templatePath("content/blog")
 	}
+	logger := newLogger()
+	md, err := blog.NewMarkdown(blog.MarkdownOptions{
+		Extensions: cfg.Blog.Markdown.Extensions,
+		Unsafe:     cfg.Blog.Markdown.Unsafe,
+		HardWraps:  cfg.Blog.Markdown.HardWraps,
+	})
+	if err != nil {
+		return nil, err
+	}
 	showDrafts := os.Getenv("APP_ENV") != "prod"
-	bs, err := blog.NewFilesStore(dir, blog.WithDrafts(showDrafts))
+	bs, err := blog.NewFilesStore(dir,
+		blog.WithDrafts(showDrafts),
+		blog.WithLogger(logger),
+		blog.WithMarkdown(md),
+		blog.WithTOCDepth(cfg.Blog.TOC.MinDepth, cfg.Blog.TOC.MaxDepth),
+		blog.WithAuthors(blogAuthors(cfg.Blog.Authors)),
+		blog.WithShortcodes(tpls, shortcodeSite{cfg}),
+		blog.WithImages(imgs),
+	)
 	if err != nil {
 		return nil, err
 	}
+	// Pick up new/edited posts without a restart.
+	go bs.Watch(context.Background(), blogWatchInterval)
 
 	return &App{
 		tpls:     tpls,
 		staticFS: http.Dir(templatePath("web/static")),
 		cfg:      cfg,
-		log:      newLogger(),
+		rt:       rt,
+		log:      logger,
 		blog:     bs,
+		preview:  previewSigner(),
+		images:   imgs,
+		assets:   manifest,
 	}, nil
 }
 
+// blogAuthors converts configured authors for the blog store.
+func blogAuthors(in []config.Author) []blog.Author {
+	out := make([]blog.Author, len(in))
+	for i, a := range in {
+		out[i] = blog.Author{ID: a.ID, Name: a.Name, Avatar: a.Avatar}
+		for _, l := range a.Links {
+			out[i].Links = append(out[i].Links, blog.Link{Label: l.Label, URL: l.Href})
+		}
+	}
+	return out
+}
+
+// blogWatchInterval is how often the blog content directory is polled for changes.
+const blogWatchInterval = 2 * time.Second
+
+// loadTemplates parses every page, partial and shortcode template into one set.
+// Their asset func maps a static URL to its fingerprinted form in m; a nil m
+// leaves URLs as they are.
+func loadTemplates(m *assets.Manifest) (*template.Template, error) {
+	t, err := template.New("site").Funcs(template.FuncMap{"asset": m.URL}).ParseFiles(
+		templatePath("web/templates/icons.html.tmpl"),
+		templatePath("web/templates/base.html.tmpl"),
+		templatePath("web/templates/home.html.tmpl"),
+		templatePath("web/templates/blog_index.html.tmpl"),
+		templatePath("web/templates/blog_post.html.tmpl"),
+		templatePath("web/templates/blog_tags.html.tmpl"),
+		templatePath("web/templates/blog_search.html.tmpl"),
+		templatePath("web/templates/blog_series.html.tmpl"),
+		templatePath("web/templates/blog_archive.html.tmpl"),
+		templatePath("web/templates/404.html.tmpl"),
+		templatePath("web/templates/500.html.tmpl"),
+		templatePath("web/templates/partials/tri_anim.html.tmpl"),
+		templatePath("web/templates/partials/hex_anim.html.tmpl"),
+		templatePath("web/templates/partials/traces.html.tmpl"),
+		templatePath("web/templates/partials/circle.html.tmpl"),
+		templatePath("web/templates/partials/_project-cards.html.tmpl"),
+		templatePath("web/templates/partials/_bookshelf.html.tmpl"),
+		templatePath("web/templates/partials/about.html.tmpl"),
+		templatePath("web/templates/partials/footer.html.tmpl"),
+		templatePath("web/templates/partials/feeds.html.tmpl"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return t.ParseGlob(templatePath(shortcodeGlob))
+}
+
 func newLogger() *slog.Logger {
 	var h slog.Handler
 	if os.Getenv("LOG_FORMAT") == "json" || os.Getenv("APP_ENV") == "prod" {
@@ -0,0 +1,109 @@
+// cmd/web/archive.go
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/brandondunbar/personal-site/internal/blog"
+)
+
+/*
+Date archive pages:
+
+	/blog/archive          years and months with post counts
+	/blog/2025             posts from 2025
+	/blog/2025/08          posts from August 2025
+	/blog/archive/undated  posts without a date
+
+Year and month pages share the /blog/ catch-all with post slugs (a pattern
+like /blog/{year} would shadow every post), so handleBlogPost hands them
+over via archivePeriod when no post matches.
+*/
+
+// archivePathRE matches "2025" and "2025/08".
+var archivePathRE = regexp.MustCompile(`^(\d{4})(?:/(0[1-9]|1[0-2]))?$`)
+
+// archivePeriod parses the part of a /blog/ path after the prefix.
+func archivePeriod(rest string) (year int, month time.Month, ok bool) {
+	m := archivePathRE.FindStringSubmatch(rest)
+	if m == nil {
+		return 0, 0, false
+	}
+	year, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		n, _ := strconv.Atoi(m[2])
+		month = time.Month(n)
+	}
+	return year, month, year > 0
+}
+
+// archiveURL is the page for a period; month 0 is the whole year.
+func archiveURL(year int, month time.Month) string {
+	if month == 0 {
+		return fmt.Sprintf("/blog/%04d", year)
+	}
+	return fmt.Sprintf("/blog/%04d/%02d", year, int(month))
+}
+
+func (a *App) handleArchive(w http.ResponseWriter, r *http.Request) {
+	data := struct {
+		TemplateData
+		Archive blog.Archive
+	}{
+		TemplateData: TemplateData{Site: a.cfg, Year: now().Year(), Title: "Archive | " + a.cfg.Title},
+		Archive:      a.blog.Archive(),
+	}
+	a.render(w, "blog_archive", data)
+}
+
+func (a *App) handleUndated(w http.ResponseWriter, r *http.Request) {
+	a.renderArchivePeriod(w, r, 0, 0)
+}
+
+// renderArchivePeriod lists the posts of a year, a month, or (year 0) the
+// undated bucket. Empty periods are 404s so the archive has no dead ends.
+func (a *App) renderArchivePeriod(w http.ResponseWriter, r *http.Request, year int, month time.Month) {
+	posts := a.blog.ByDate(year, month)
+	if len(posts) == 0 {
+		a.renderNotFound(w, r)
+		return
+	}
+	var heading string
+	switch {
+	case year == 0:
+		heading = "Undated"
+	case month == 0:
+		heading = strconv.Itoa(year)
+	default:
+		heading = month.String() + " " + strconv.Itoa(year)
+	}
+	data := struct {
+		TemplateData
+		Heading string
+		Posts   []blog.Post
+	}{
+		TemplateData: TemplateData{Site: a.cfg, Year: now().Year(), Title: heading + " | " + a.cfg.Title},
+		Heading:      heading,
+		Posts:        posts,
+	}
+	a.render(w, "blog_archive_period", data)
+}
+
+// archivePaths lists the archive pages for the static export.
+func archivePaths(arc blog.Archive) []string {
+	paths := []string{"/blog/archive"}
+	for _, y := range arc.Years {
+		paths = append(paths, archiveURL(y.Year, 0))
+		for _, m := range y.Months {
+			paths = append(paths, archiveURL(m.Year, m.Month))
+		}
+	}
+	if arc.Undated > 0 {
+		paths = append(paths, "/blog/archive/undated")
+	}
+	return paths
+}
@@ -0,0 +1,377 @@
+// cmd/web/blog.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/brandondunbar/personal-site/internal/blog"
+)
+
+// Pager describes the current blog index page and its neighbours.
+type Pager struct {
+	Page, Pages int
+	Total       int
+	Prev, Next  string // URLs; empty at either end
+}
+
+// pageURL is the canonical URL of index page n.
+func pageURL(n int) string {
+	if n <= 1 {
+		return "/blog"
+	}
+	return "/blog/page/" + strconv.Itoa(n)
+}
+
+// GET /blog, /blog/page/{n}; /blog?page=N redirects
+func (a *App) handleBlogIndex(w http.ResponseWriter, r *http.Request) {
+	page := 1
+	raw := r.PathValue("n")
+	if raw == "" {
+		raw = r.URL.Query().Get("page")
+	}
+	if raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			a.renderNotFound(w, r)
+			return
+		}
+		page = n
+	}
+
+	size := a.cfg.Blog.PerPage()
+	posts, total := a.blog.Page(page, size)
+	pages := max(1, (total+size-1)/size)
+	if page > pages {
+		a.renderNotFound(w, r)
+		return
+	}
+	// One URL per page: /blog?page=2, /blog/page/1 and /blog/page/02
+	// redirect to pageURL.
+	if q := r.URL.Query(); r.URL.Path != pageURL(page) || q.Has("page") {
+		q.Del("page")
+		target := pageURL(page)
+		if len(q) > 0 {
+			target += "?" + q.Encode()
+		}
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+		return
+	}
+	pager := Pager{Page: page, Pages: pages, Total: total}
+	if page > 1 {
+		pager.Prev = pageURL(page - 1)
+	}
+	if page < pages {
+		pager.Next = pageURL(page + 1)
+	}
+
+	title := "Blog | " + a.cfg.Title
+	if page > 1 {
+		title = "Blog (page " + strconv.Itoa(page) + ") | " + a.cfg.Title
+	}
+	data := struct {
+		TemplateData
+		Posts  []blog.Post
+		Pager  Pager
+		Search bool // /blog/search exists; not in a static export
+	}{
+		TemplateData: TemplateData{Site: a.cfg, Year: now().Year(), Title: title},
+		Posts:        posts,
+		Pager:        pager,
+		Search:       !a.static,
+	}
+	a.render(w, "blog_index", data)
+}
+
+// relatedLimit is how many related posts close out a post.
+const relatedLimit = 3
+
+// postCanonical is the URL search engines should credit: the original for
+// cross-posted articles, otherwise this site's page.
+func postCanonical(base string, p blog.Post) string {
+	if p.Canonical != "" {
+		return p.Canonical
+	}
+	return base + "/blog/" + p.Slug
+}
+
+// GET /blog/{slug}
+func (a *App) handleBlogPost(w http.ResponseWriter, r *http.Request) {
+	slug := strings.TrimPrefix(r.URL.Path, "/blog/")
+	if year, month, ok := archivePeriod(slug); ok {
+		// A post that happens to be called "2025" still wins.
+		if _, isPost := a.blog.BySlug(slug); !isPost {
+			a.renderArchivePeriod(w, r, year, month)
+			return
+		}
+	}
+	if post, name, ok := strings.Cut(slug, "/"); ok {
+		a.serveBundleAsset(w, r, post, name)
+		return
+	}
+	if slug == "" {
+		a.renderNotFound(w, r)
+		return
+	}
+	post, ok := a.blog.BySlug(slug)
+	isPreview := false
+	if !ok {
+		post, isPreview = a.previewPost(w, r, slug)
+		ok = isPreview
+	}
+	if !ok {
+		if canonical, ok := a.blog.Alias(slug); ok {
+			http.Redirect(w, r, "/blog/"+canonical, http.StatusMovedPermanently)
+			return
+		}
+		a.renderNotFound(w, r)
+		return
+	}
+	data := struct {
+		TemplateData
+		Post      blog.Post
+		Related   []blog.Post
+		Preview   bool
+		Canonical string
+	}{
+		TemplateData: TemplateData{Site: a.cfg, Year: now().Year()},
+		Post:         post,
+		Related:      a.blog.Related(post.Slug, relatedLimit),
+		Preview:      isPreview,
+		Canonical:    postCanonical(a.rt.BaseURL, post),
+	}
+	a.render(w, "blog_post", data)
+}
+
+// GET /blog/tags
+func (a *App) handleTags(w http.ResponseWriter, r *http.Request) {
+	data := struct {
+		TemplateData
+		Tags []blog.Tag
+	}{
+		TemplateData: TemplateData{Site: a.cfg, Year: now().Year(), Title: "Tags | " + a.cfg.Title},
+		Tags:         a.blog.Tags(),
+	}
+	a.render(w, "blog_tags", data)
+}
+
+// GET /blog/tags/{tag}
+// Non-canonical spellings ("/blog/tags/Go%20Lang") redirect to the slug form.
+func (a *App) handleTag(w http.ResponseWriter, r *http.Request) {
+	raw := r.PathValue("tag")
+	slug := blog.TagSlug(raw)
+	if slug == "" {
+		a.renderNotFound(w, r)
+		return
+	}
+	if raw != slug {
+		http.Redirect(w, r, "/blog/tags/"+slug, http.StatusMovedPermanently)
+		return
+	}
+	posts := a.blog.ByTag(slug)
+	if len(posts) == 0 {
+		a.renderNotFound(w, r)
+		return
+	}
+	tag := a.tagBySlug(slug, len(posts))
+	data := struct {
+		TemplateData
+		Tag   blog.Tag
+		Posts []blog.Post
+	}{
+		TemplateData: TemplateData{Site: a.cfg, Year: now().Year(), Title: "#" + tag.Name + " | " + a.cfg.Title},
+		Tag:          tag,
+		Posts:        posts,
+	}
+	a.render(w, "blog_tag", data)
+}
+
+// tagBySlug looks up a tag's display name; count is used if the tag
+// vanished between queries (e.g. a reload).
+func (a *App) tagBySlug(slug string, count int) blog.Tag {
+	for _, t := range a.blog.Tags() {
+		if t.Slug == slug {
+			return t
+		}
+	}
+	return blog.Tag{Name: slug, Slug: slug, Count: count}
+}
+
+// serveBundleAsset serves /blog/{slug}/{name} from a page bundle. A bare
+// /blog/{slug}/ redirects to the post itself.
+func (a *App) serveBundleAsset(w http.ResponseWriter, r *http.Request, slug, name string) {
+	if name == "" {
+		if _, ok := a.blog.BySlug(slug); ok {
+			http.Redirect(w, r, "/blog/"+slug, http.StatusMovedPermanently)
+			return
+		}
+		a.renderNotFound(w, r)
+		return
+	}
+	fsys, ok := a.blog.BundleFS(slug)
+	if !ok {
+		a.renderNotFound(w, r)
+		return
+	}
+	if _, published := a.blog.BySlug(slug); !published {
+		if !a.canPreviewAssets(r, slug) {
+			a.renderNotFound(w, r)
+			return
+		}
+		setPreviewHeaders(w)
+	}
+	if info, err := fs.Stat(fsys, name); err != nil || info.IsDir() {
+		a.renderNotFound(w, r)
+		return
+	}
+	http.ServeFileFS(w, r, fsys, name)
+}
+
+// handleSeries lists the posts of a series in reading order. Like tags,
+// non-canonical names redirect to the slug form.
+func (a *App) handleSeries(w http.ResponseWriter, r *http.Request) {
+	raw := r.PathValue("name")
+	if strings.TrimSpace(raw) == "" {
+		a.renderNotFound(w, r)
+		return
+	}
+	if slug := blog.Slugify(raw); raw != slug {
+		http.Redirect(w, r, "/blog/series/"+slug, http.StatusMovedPermanently)
+		return
+	}
+	posts := a.blog.Series(raw)
+	if len(posts) == 0 {
+		a.renderNotFound(w, r)
+		return
+	}
+	series := posts[0].Series
+	data := struct {
+		TemplateData
+		Series *blog.SeriesNav
+		Posts  []blog.Post
+	}{
+		TemplateData: TemplateData{Site: a.cfg, Year: now().Year(), Title: series.Name + " | " + a.cfg.Title},
+		Series:       series,
+		Posts:        posts,
+	}
+	a.render(w, "blog_series", data)
+}
+
+// seriesSlugs lists each series once, in order of first appearance in posts.
+func seriesSlugs(posts []blog.Post) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, p := range posts {
+		if p.Series != nil && !seen[p.Series.Slug] {
+			seen[p.Series.Slug] = true
+			out = append(out, p.Series.Slug)
+		}
+	}
+	return out
+}
+
+// searchLimit caps how many hits a search returns.
+const searchLimit = 20
+
+// GET /blog/search?q=
+// Clients sending Accept: application/json get JSON for instant search.
+func (a *App) handleSearch(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	var results []blog.SearchResult
+	if q != "" {
+		results = a.blog.Search(q, searchLimit)
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		type hit struct {
+			Title   string   `json:"title"`
+			URL     string   `json:"url"`
+			Date    string   `json:"date,omitempty"`
+			Summary string   `json:"summary,omitempty"`
+			Snippet string   `json:"snippet"` // HTML with <mark>ed matches
+			Tags    []string `json:"tags,omitempty"`
+		}
+		out := struct {
+			Query   string `json:"query"`
+			Results []hit  `json:"results"`
+		}{Query: q, Results: []hit{}}
+		for _, res := range results {
+			h := hit{
+				Title:   res.Post.Title,
+				URL:     "/blog/" + res.Post.Slug,
+				Summary: res.Post.Summary,
+				Snippet: string(res.Snippet),
+				Tags:    res.Post.Tags,
+			}
+			if !res.Post.Date.IsZero() {
+				h.Date = res.Post.Date.Format("2006-01-02")
+			}
+			out.Results = append(out.Results, h)
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Vary", "Accept")
+		_ = json.NewEncoder(w).Encode(out)
+		return
+	}
+
+	title := "Search | " + a.cfg.Title
+	if q != "" {
+		title = q + " — Search | " + a.cfg.Title
+	}
+	data := struct {
+		TemplateData
+		Query   string
+		Results []blog.SearchResult
+	}{
+		TemplateData: TemplateData{Site: a.cfg, Year: now().Year(), Title: title},
+		Query:        q,
+		Results:      results,
+	}
+	w.Header().Set("Vary", "Accept")
+	a.render(w, "blog_search", data)
+}
+
+// GET /blog/graph.json
+// Published posts and the wiki links between them, for link-graph views.
+func (a *App) handleGraph(w http.ResponseWriter, r *http.Request) {
+	type node struct {
+		ID    string   `json:"id"` // post slug
+		Title string   `json:"title"`
+		URL   string   `json:"url"`
+		Tags  []string `json:"tags,omitempty"`
+	}
+	type edge struct {
+		Source string `json:"source"`
+		Target string `json:"target"`
+	}
+	g := a.blog.Graph()
+	out := struct {
+		Nodes []node `json:"nodes"`
+		Edges []edge `json:"edges"`
+	}{Nodes: []node{}, Edges: []edge{}}
+	for _, n := range g.Nodes {
+		out.Nodes = append(out.Nodes, node{ID: n.Slug, Title: n.Title, URL: "/blog/" + n.Slug, Tags: n.Tags})
+	}
+	for _, e := range g.Edges {
+		out.Edges = append(out.Edges, edge{Source: e.From, Target: e.To})
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+// GET /blog/syntax.css
+// Stylesheet for highlighted code blocks in the configured palettes.
+func (a *App) handleSyntaxCSS(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := blog.HighlightCSS(&buf, a.cfg.Blog.Syntax.Light, a.cfg.Blog.Syntax.Dark); err != nil {
+		http.Error(w, "syntax css error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	_, _ = buf.WriteTo(w)
+}
@@ -0,0 +1,444 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/brandondunbar/personal-site/internal/config"
+)
+
+var tagPosts = map[string]string{
+	"a.md": "---\ntitle: Alpha\ndate: 2025-08-01\ntags: [Go Lang, web]\n---\nalpha",
+	"b.md": "---\ntitle: Beta\ndate: 2025-08-02\ntags: [go-lang]\n---\nbeta",
+}
+
+func TestTags_ListsTagsWithCounts(t *testing.T) {
+	app := mustBlogApp(t, tagPosts)
+
+	resp, body := get(t, app.Routes(), "/blog/tags")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if !strings.Contains(body, `href="/blog/tags/go-lang"`) || !strings.Contains(body, "(2)") {
+		t.Fatalf("tag index missing go-lang with count 2: %q", body)
+	}
+	if !strings.Contains(body, `href="/blog/tags/web"`) {
+		t.Fatalf("tag index missing web: %q", body)
+	}
+}
+
+func TestTag_ListsMatchingPosts(t *testing.T) {
+	app := mustBlogApp(t, tagPosts)
+
+	resp, body := get(t, app.Routes(), "/blog/tags/go-lang")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if !strings.Contains(body, "Alpha") || !strings.Contains(body, "Beta") {
+		t.Fatalf("tag page missing posts: %q", body)
+	}
+
+	resp, _ = get(t, app.Routes(), "/blog/tags/web")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("web status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestTag_NonCanonicalRedirects(t *testing.T) {
+	app := mustBlogApp(t, tagPosts)
+
+	resp, _ := get(t, app.Routes(), "/blog/tags/Go%20Lang")
+	if resp.StatusCode != http.StatusMovedPermanently {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusMovedPermanently)
+	}
+	if loc := resp.Header.Get("Location"); loc != "/blog/tags/go-lang" {
+		t.Fatalf("Location = %q, want /blog/tags/go-lang", loc)
+	}
+}
+
+func TestTag_Unknown404(t *testing.T) {
+	app := mustBlogApp(t, tagPosts)
+
+	resp, _ := get(t, app.Routes(), "/blog/tags/rust")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestBlogPost_LinksTags(t *testing.T) {
+	app := mustBlogApp(t, tagPosts)
+
+	resp, body := get(t, app.Routes(), "/blog/alpha")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if !strings.Contains(body, `<a href="/blog/tags/go-lang">#Go Lang</a>`) {
+		t.Fatalf("post missing tag link: %q", body)
+	}
+}
+
+func TestBlogIndex_Paginates(t *testing.T) {
+	app := mustBlogApp(t, map[string]string{
+		"a.md": "---\ntitle: Alpha\ndate: 2025-08-01\n---\na",
+		"b.md": "---\ntitle: Beta\ndate: 2025-08-02\n---\nb",
+		"c.md": "---\ntitle: Gamma\ndate: 2025-08-03\n---\nc",
+	})
+	app.cfg.Blog.PageSize = 2
+	h := app.Routes()
+
+	_, body := get(t, h, "/blog")
+	if !strings.Contains(body, "Gamma") || !strings.Contains(body, "Beta") || strings.Contains(body, "Alpha") {
+		t.Fatalf("page 1 has wrong posts: %q", body)
+	}
+	if !strings.Contains(body, `<link rel="next" href="/blog/page/2">`) || strings.Contains(body, `rel="prev"`) {
+		t.Fatalf("page 1 head links wrong: %q", body)
+	}
+
+	resp, body := get(t, h, "/blog/page/2")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("page 2: status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if !strings.Contains(body, "Alpha") || strings.Contains(body, "Gamma") {
+		t.Fatalf("page 2: wrong posts: %q", body)
+	}
+	if !strings.Contains(body, `<link rel="prev" href="/blog">`) {
+		t.Fatalf("page 2: missing rel=prev: %q", body)
+	}
+
+	// Every other spelling of a page redirects to its one URL.
+	for path, want := range map[string]string{
+		"/blog?page=2":           "/blog/page/2",
+		"/blog?page=1":           "/blog",
+		"/blog/page/1":           "/blog",
+		"/blog/page/02":          "/blog/page/2",
+		"/blog?page=2&utm=x":     "/blog/page/2?utm=x",
+		"/blog/page/2?page=9999": "/blog/page/2",
+	} {
+		resp, _ := get(t, h, path)
+		if resp.StatusCode != http.StatusMovedPermanently || resp.Header.Get("Location") != want {
+			t.Fatalf("%s: status %d, Location %q, want 301 to %s", path, resp.StatusCode, resp.Header.Get("Location"), want)
+		}
+	}
+
+	for _, path := range []string{"/blog/page/3", "/blog/page/0", "/blog?page=x"} {
+		if resp, _ := get(t, h, path); resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("%s: status = %d, want 404", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestSearch_HTMLAndJSON(t *testing.T) {
+	app := mustBlogApp(t, tagPosts)
+	app.cfg.Head.Styles = []string{"/static/css/site.css"}
+	h := app.Routes()
+
+	resp, body := get(t, h, "/blog/search?q=alph")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	// The search form and highlighted snippets are styled by the site CSS.
+	css := `<link rel="stylesheet" href="` + app.assets.URL("/static/css/site.css") + `">`
+	for _, path := range []string{"/blog/search?q=alph", "/blog"} {
+		if _, page := get(t, h, path); !strings.Contains(page, css) {
+			t.Fatalf("%s: missing %s", path, css)
+		}
+	}
+	if !strings.Contains(body, `href="/blog/alpha"`) || strings.Contains(body, `href="/blog/beta"`) {
+		t.Fatalf("html results wrong: %q", body)
+	}
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/blog/search?q=go", nil)
+	req.Header.Set("Accept", "application/json")
+	h.ServeHTTP(rr, req)
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Fatalf("Content-Type = %q, want application/json", ct)
+	}
+	var out struct {
+		Query   string
+		Results []struct{ Title, URL string }
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &out); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if out.Query != "go" || len(out.Results) != 2 || out.Results[0].URL != "/blog/beta" {
+		t.Fatalf("json = %+v", out)
+	}
+}
+
+func TestBlogPages_LinkSiteStyles(t *testing.T) {
+	app := mustBlogApp(t, map[string]string{
+		"a.md": "---\ntitle: Part One\ndate: 2025-08-01\ntags: [go]\nseries: Deep Dive\n---\none",
+	})
+	app.cfg.Head.Styles = []string{"/static/css/site.css"}
+	h := app.Routes()
+
+	// Heading anchors, toc, series box and the rest are styled by the site CSS.
+	css := `<link rel="stylesheet" href="` + app.assets.URL("/static/css/site.css") + `">`
+	for _, path := range []string{
+		"/blog", "/blog/part-one", "/blog/tags", "/blog/tags/go", "/blog/series/deep-dive",
+		"/blog/archive", "/blog/2025", "/blog/search?q=one",
+	} {
+		resp, body := get(t, h, path)
+		if resp.StatusCode != http.StatusOK || !strings.Contains(body, css) {
+			t.Fatalf("%s: status %d, missing %s", path, resp.StatusCode, css)
+		}
+	}
+}
+
+func TestBlogPages_FollowSiteTheme(t *testing.T) {
+	app := mustBlogApp(t, map[string]string{
+		"a.md": "---\ntitle: Part One\ndate: 2025-08-01\ntags: [go]\nseries: Deep Dive\n---\none",
+	})
+	app.cfg.Head.Scripts = []string{"/static/js/main.js"}
+	h := app.Routes()
+
+	// syntax.css switches on html[data-theme], which the bootstrap sets from
+	// the toggle's saved choice and theme.js (imported by main.js) maintains.
+	for _, path := range []string{
+		"/blog/part-one", "/blog/tags", "/blog/tags/go", "/blog/series/deep-dive",
+		"/blog/archive", "/blog/2025",
+	} {
+		_, body := get(t, h, path)
+		for _, want := range []string{`localStorage.getItem("theme")`, `<script type="module" src="/static/js/main.js"></script>`} {
+			if !strings.Contains(body, want) {
+				t.Fatalf("%s: missing %s", path, want)
+			}
+		}
+	}
+}
+
+func TestBlogPost_RendersTOC(t *testing.T) {
+	app := mustBlogApp(t, map[string]string{
+		"g.md": "---\ntitle: Guide\n---\n## Setup\n\n### Install\n\n## Usage\n",
+	})
+
+	_, body := get(t, app.Routes(), "/blog/guide")
+	if !strings.Contains(body, `<nav class="toc"`) || !strings.Contains(body, `<a href="#install">Install</a>`) {
+		t.Fatalf("TOC not rendered: %q", body)
+	}
+}
+
+func TestBlog_ShowsReadingTimeAndExcerpt(t *testing.T) {
+	app := mustBlogApp(t, map[string]string{
+		"a.md": "---\ntitle: Alpha\ndate: 2025-08-01\n---\nFirst paragraph here.\n\nSecond paragraph.\n",
+	})
+
+	_, body := get(t, app.Routes(), "/blog")
+	if !strings.Contains(body, "1 min read") || !strings.Contains(body, "<p>First paragraph here.</p>") {
+		t.Fatalf("index missing reading time or excerpt: %q", body)
+	}
+	_, body = get(t, app.Routes(), "/blog/alpha")
+	if !strings.Contains(body, `title="5 words">1 min read`) {
+		t.Fatalf("post missing reading time: %q", body)
+	}
+}
+
+var seriesPosts = map[string]string{
+	"a.md": "---\ntitle: Part One\ndate: 2025-08-01\nseries: Deep Dive\nseries_order: 1\n---\none",
+	"b.md": "---\ntitle: Part Two\ndate: 2025-08-02\nseries: Deep Dive\nseries_order: 2\n---\ntwo",
+}
+
+func TestSeries_ListsPartsInOrder(t *testing.T) {
+	app := mustBlogApp(t, seriesPosts)
+
+	resp, body := get(t, app.Routes(), "/blog/series/deep-dive")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	one, two := strings.Index(body, "Part One"), strings.Index(body, "Part Two")
+	if one < 0 || two < 0 || one > two {
+		t.Fatalf("series page out of order: %q", body)
+	}
+
+	resp, _ = get(t, app.Routes(), "/blog/series/Deep%20Dive")
+	if resp.StatusCode != http.StatusMovedPermanently || resp.Header.Get("Location") != "/blog/series/deep-dive" {
+		t.Fatalf("redirect = %d %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	resp, _ = get(t, app.Routes(), "/blog/series/nope")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("unknown series status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestBlogPost_SeriesBox(t *testing.T) {
+	app := mustBlogApp(t, seriesPosts)
+
+	_, body := get(t, app.Routes(), "/blog/part-two")
+	if !strings.Contains(body, `Part 2 of 2 in <a href="/blog/series/deep-dive">Deep Dive</a>`) {
+		t.Fatalf("post missing series box: %q", body)
+	}
+	if !strings.Contains(body, `<a rel="prev" href="/blog/part-one">`) || strings.Contains(body, `rel="next"`) {
+		t.Fatalf("series nav wrong: %q", body)
+	}
+}
+
+func TestBlogPost_ListsRelated(t *testing.T) {
+	app := mustBlogApp(t, tagPosts)
+
+	_, body := get(t, app.Routes(), "/blog/alpha")
+	i := strings.Index(body, "Related posts")
+	if i < 0 || !strings.Contains(body[i:], `href="/blog/beta"`) {
+		t.Fatalf("post missing related beta: %q", body)
+	}
+}
+
+func TestBlogPost_AliasRedirects(t *testing.T) {
+	app := mustBlogApp(t, map[string]string{
+		"a.md": "---\ntitle: New Title\naliases: [old-title]\n---\na",
+	})
+
+	resp, _ := get(t, app.Routes(), "/blog/old-title")
+	if resp.StatusCode != http.StatusMovedPermanently {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusMovedPermanently)
+	}
+	if loc := resp.Header.Get("Location"); loc != "/blog/new-title" {
+		t.Fatalf("Location = %q, want /blog/new-title", loc)
+	}
+}
+
+func TestArchive_Pages(t *testing.T) {
+	app := mustBlogApp(t, map[string]string{
+		"a.md":    "---\ntitle: Alpha\ndate: 2025-08-01\n---\na",
+		"b.md":    "---\ntitle: Beta\ndate: 2024-02-01\n---\nb",
+		"c.md":    "---\ntitle: Gamma\n---\nc",
+		"2023.md": "---\ntitle: \"2023\"\ndate: 2025-01-01\n---\na post, not a year",
+	})
+	h := app.Routes()
+
+	_, body := get(t, h, "/blog/archive")
+	for _, want := range []string{`href="/blog/2025"`, `href="/blog/2025/08"`, `href="/blog/2024/02"`, `href="/blog/archive/undated"`} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("archive missing %s: %q", want, body)
+		}
+	}
+
+	for path, want := range map[string]string{
+		"/blog/2025":            "Alpha",
+		"/blog/2025/08":         "Alpha",
+		"/blog/2024":            "Beta",
+		"/blog/archive/undated": "Gamma",
+		"/blog/2023":            "a post, not a year",
+	} {
+		resp, body := get(t, h, path)
+		if resp.StatusCode != http.StatusOK || !strings.Contains(body, want) {
+			t.Fatalf("%s: status %d, missing %q", path, resp.StatusCode, want)
+		}
+	}
+	for _, path := range []string{"/blog/2025/07", "/blog/2025/13", "/blog/1999"} {
+		if resp, _ := get(t, h, path); resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("%s: status %d, want 404", path, resp.StatusCode)
+		}
+	}
+}
+
+var bundlePosts = map[string]string{
+	"trip/index.md": "---\ntitle: Trip\ndate: 2025-08-01\n---\n![map](map.png)",
+	"trip/map.png":  "not really a png",
+	"2024/old.md":   "---\ntitle: Old\ndate: 2024-01-01\n---\nold",
+	"trip/.secret":  "hidden",
+	"trip/extra.md": "---\ntitle: Extra\n---\nsource only",
+}
+
+func TestBlogPost_ServesBundleAssets(t *testing.T) {
+	app := mustBlogApp(t, bundlePosts)
+	h := app.Routes()
+
+	_, body := get(t, h, "/blog/trip")
+	if !strings.Contains(body, `src="/blog/trip/map.png"`) {
+		t.Fatalf("bundle image not rewritten: %q", body)
+	}
+	resp, body := get(t, h, "/blog/trip/map.png")
+	if resp.StatusCode != http.StatusOK || body != "not really a png" {
+		t.Fatalf("asset: status %d body %q", resp.StatusCode, body)
+	}
+	if resp, _ := get(t, h, "/blog/old"); resp.StatusCode != http.StatusOK {
+		t.Fatalf("nested post status = %d", resp.StatusCode)
+	}
+	for _, path := range []string{"/blog/trip/index.md", "/blog/trip/extra.md", "/blog/trip/.secret", "/blog/nope/", "/blog/old/map.png"} {
+		if resp, _ := get(t, h, path); resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("%s: status %d, want 404", path, resp.StatusCode)
+		}
+	}
+	for _, path := range []string{"/blog/trip/", "/blog/old/"} {
+		resp, _ := get(t, h, path)
+		if want := strings.TrimSuffix(path, "/"); resp.StatusCode != http.StatusMovedPermanently || resp.Header.Get("Location") != want {
+			t.Fatalf("%s: status %d, Location %q, want 301 to %s", path, resp.StatusCode, resp.Header.Get("Location"), want)
+		}
+	}
+}
+
+func TestBlogPost_RichMeta(t *testing.T) {
+	app := mustBlogApp(t, map[string]string{
+		"a.md": "---\ntitle: Own\ndate: 2025-08-01\ndescription: Own page.\ncover: {image: /static/c.jpg, alt: Cover}\n---\na",
+		"b.md": "---\ntitle: Elsewhere\ndate: 2025-08-02\ncanonical: https://dev.to/x/elsewhere\nlang: de\n---\nb",
+	})
+	h := app.Routes()
+
+	_, body := get(t, h, "/blog/own")
+	for _, want := range []string{
+		`<html lang="en">`,
+		`<meta name="description" content="Own page.">`,
+		`<link rel="canonical" href="https://example.com/blog/own">`,
+		`<img src="/static/c.jpg" alt="Cover">`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("own post missing %s: %q", want, body)
+		}
+	}
+
+	_, body = get(t, h, "/blog/elsewhere")
+	if !strings.Contains(body, `<html lang="de">`) || !strings.Contains(body, `<link rel="canonical" href="https://dev.to/x/elsewhere">`) {
+		t.Fatalf("cross-posted post missing lang or canonical: %q", body)
+	}
+	_, body = get(t, h, "/sitemap.xml")
+	if strings.Contains(body, "/blog/elsewhere") || !strings.Contains(body, "/blog/own") {
+		t.Fatalf("sitemap should list own posts only: %q", body)
+	}
+}
+
+func TestBlogAuthors_FromConfig(t *testing.T) {
+	got := blogAuthors([]config.Author{{ID: "e", Name: "Elliot", Links: []config.AuthorLink{{Href: "https://x.dev", Label: "Site"}}}})
+	if len(got) != 1 || got[0].Name != "Elliot" || got[0].Links[0].URL != "https://x.dev" {
+		t.Fatalf("blogAuthors = %+v", got)
+	}
+}
+
+func TestBlogPost_BacklinksAndGraph(t *testing.T) {
+	app := mustBlogApp(t, map[string]string{
+		"a.md": "---\ntitle: Alpha\ndate: 2025-08-01\n---\nalpha",
+		"b.md": "---\ntitle: Beta\ndate: 2025-08-02\n---\nbuilds on [[alpha|the first post]]",
+	})
+	h := app.Routes()
+
+	_, body := get(t, h, "/blog/beta")
+	if !strings.Contains(body, `builds on <a href="/blog/alpha">the first post</a>`) {
+		t.Fatalf("wiki link not rendered: %q", body)
+	}
+	_, body = get(t, h, "/blog/alpha")
+	i := strings.Index(body, "Referenced by")
+	if i < 0 || !strings.Contains(body[i:], `<a href="/blog/beta">Beta</a>`) {
+		t.Fatalf("post missing backlink from beta: %q", body)
+	}
+
+	resp, body := get(t, h, "/blog/graph.json")
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Fatalf("Content-Type = %q, want application/json", ct)
+	}
+	var g struct {
+		Nodes []struct{ ID, Title, URL string }
+		Edges []struct{ Source, Target string }
+	}
+	if err := json.Unmarshal([]byte(body), &g); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if len(g.Nodes) != 2 || g.Nodes[0].ID != "beta" || g.Nodes[0].URL != "/blog/beta" {
+		t.Fatalf("nodes = %+v", g.Nodes)
+	}
+	if len(g.Edges) != 1 || g.Edges[0].Source != "beta" || g.Edges[0].Target != "alpha" {
+		t.Fatalf("edges = %+v", g.Edges)
+	}
+}
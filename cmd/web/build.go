@@ -0,0 +1,317 @@
+// cmd/web/build.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/brandondunbar/personal-site/internal/config"
+)
+
+/*
+Static export ("web build").
+
+Renders every page through App.Routes() in-process and writes the responses
+to an output directory laid out for plain object storage:
+
+	/            -> index.html
+	/blog/hello  -> blog/hello/index.html
+	/blog/feed.atom, /sitemap.xml, ... keep their names
+
+web/static is copied to <out>/static, each file under both its plain and its
+fingerprinted name, plus 404.html and 500.html. Page bundle assets land next
+to their post (blog/<slug>/photo.jpg), and the resized image variants the
+pages use in <out>/static/_img.
+
+Search needs the server, so /blog/search is not exported and the exported
+pages leave out the search form.
+*/
+
+// BuildStats summarizes a static export.
+type BuildStats struct {
+	Pages       int
+	PageBytes   int64
+	Static      int
+	StaticBytes int64
+}
+
+func (s BuildStats) String() string {
+	return fmt.Sprintf("wrote %d pages (%d bytes) and %d static files (%d bytes)",
+		s.Pages, s.PageBytes, s.Static, s.StaticBytes)
+}
+
+// runBuild implements the "build" subcommand.
+func runBuild(rt config.Runtime, args []string) error {
+	fs := flag.NewFlagSet("build", flag.ContinueOnError)
+	out := fs.String("out", "dist", "output directory")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	app, err := NewApp(rt)
+	if err != nil {
+		return err
+	}
+	stats, err := app.Build(*out)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("build: %s into %s\n", stats, *out)
+	return nil
+}
+
+// Build renders the site into dir. Any page that should exist but does not
+// answer 200 fails the build; all such pages are reported together.
+func (a *App) Build(dir string) (BuildStats, error) {
+	var stats BuildStats
+	a.static = true
+	defer func() { a.static = false }()
+	h := a.Routes()
+
+	var failed []string
+	variants := make(map[string]bool) // image variant files the pages use
+	write := func(urlPath string, wantStatus int, file string) {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, urlPath, nil))
+		if rr.Code != wantStatus {
+			failed = append(failed, fmt.Sprintf("%s: status %d, want %d", urlPath, rr.Code, wantStatus))
+			return
+		}
+		if file == "" {
+			file = outputFile(urlPath, rr.Header().Get("Content-Type"))
+		}
+		for _, m := range variantRefRE.FindAllSubmatch(rr.Body.Bytes(), -1) {
+			variants[string(m[1])] = true
+		}
+		n, err := writeFile(filepath.Join(dir, file), rr.Body)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", urlPath, err))
+			return
+		}
+		stats.Pages++
+		stats.PageBytes += n
+	}
+
+	for _, p := range a.buildPaths() {
+		write(p, http.StatusOK, "")
+	}
+	// Object storage can't answer 301, so aliases get a redirect page.
+	for _, p := range a.blog.All() {
+		for _, alias := range p.Aliases {
+			n, err := writeFile(filepath.Join(dir, outputFile("/blog/"+alias, "text/html")), strings.NewReader(redirectPage(a.rt.BaseURL+"/blog/"+p.Slug)))
+			if err != nil {
+				failed = append(failed, fmt.Sprintf("/blog/%s: %v", alias, err))
+				continue
+			}
+			stats.Pages++
+			stats.PageBytes += n
+		}
+	}
+	write("/_build/not-found", http.StatusNotFound, "404.html")
+	write("/_test/500", http.StatusInternalServerError, "500.html")
+
+	if len(failed) > 0 {
+		return stats, fmt.Errorf("build: %d page(s) failed:\n  %s", len(failed), strings.Join(failed, "\n  "))
+	}
+
+	n, size, err := copyFS(a.staticFS, "/", filepath.Join(dir, "static"))
+	if err != nil {
+		return stats, fmt.Errorf("build: copy static: %w", err)
+	}
+	stats.Static, stats.StaticBytes = n, size
+
+	// Pages link to the fingerprinted names (see internal/assets).
+	for name, fp := range a.assets.Files() {
+		f, err := a.staticFS.Open("/" + name)
+		if err != nil {
+			return stats, fmt.Errorf("build: copy static: %w", err)
+		}
+		size, err := writeFile(filepath.Join(dir, "static", filepath.FromSlash(fp)), f)
+		f.Close()
+		if err != nil {
+			return stats, fmt.Errorf("build: copy static: %w", err)
+		}
+		stats.Static++
+		stats.StaticBytes += size
+	}
+
+	// Only variants the exported pages use: the cache also holds variants of
+	// hidden posts' images and of images that have since changed.
+	if a.images != nil {
+		for name := range variants {
+			f, err := os.Open(filepath.Join(a.images.Dir(), name))
+			if err != nil {
+				return stats, fmt.Errorf("build: copy image variants: %w", err)
+			}
+			size, err := writeFile(filepath.Join(dir, filepath.FromSlash(strings.Trim(imagePrefix, "/")), name), f)
+			f.Close()
+			if err != nil {
+				return stats, fmt.Errorf("build: copy image variants: %w", err)
+			}
+			stats.Static++
+			stats.StaticBytes += size
+		}
+	}
+
+	for _, p := range a.blog.All() {
+		fsys, ok := a.blog.BundleFS(p.Slug)
+		if !ok {
+			continue
+		}
+		n, size, err := copyBundle(fsys, filepath.Join(dir, "blog", p.Slug))
+		if err != nil {
+			return stats, fmt.Errorf("build: copy bundle %s: %w", p.Slug, err)
+		}
+		stats.Static += n
+		stats.StaticBytes += size
+	}
+	return stats, nil
+}
+
+// variantRefRE finds image variant URLs (see internal/images) in a page.
+var variantRefRE = regexp.MustCompile(regexp.QuoteMeta(imagePrefix) + `([a-z0-9][a-z0-9-]*\.[a-z]+)`)
+
+// buildPaths lists every URL the static site serves.
+func (a *App) buildPaths() []string {
+	paths := []string{
+		"/",
+		"/blog",
+		"/blog/feed.atom",
+		"/blog/feed.xml",
+		"/blog/feed.json",
+		"/blog/tags",
+		"/blog/syntax.css",
+		"/blog/graph.json",
+		"/sitemap.xml",
+		"/robots.txt",
+	}
+
+	posts := a.blog.All()
+	size := a.cfg.Blog.PerPage()
+	for n := 2; (n-1)*size < len(posts); n++ {
+		paths = append(paths, pageURL(n))
+	}
+	for _, p := range posts {
+		paths = append(paths, "/blog/"+p.Slug)
+	}
+	for _, t := range a.blog.Tags() {
+		paths = append(paths, "/blog/tags/"+t.Slug, "/blog/tags/"+t.Slug+"/feed.atom")
+	}
+	for _, slug := range seriesSlugs(posts) {
+		paths = append(paths, "/blog/series/"+slug)
+	}
+	paths = append(paths, archivePaths(a.blog.Archive())...)
+	return paths
+}
+
+// redirectPage is a static stand-in for a 301: browsers follow the refresh
+// and crawlers take the canonical link.
+func redirectPage(target string) string {
+	u := html.EscapeString(target)
+	return `<!doctype html><html lang="en"><head><meta charset="utf-8">` +
+		`<title>Redirecting…</title>` +
+		`<link rel="canonical" href="` + u + `">` +
+		`<meta name="robots" content="noindex">` +
+		`<meta http-equiv="refresh" content="0; url=` + u + `">` +
+		`</head><body><p>Moved to <a href="` + u + `">` + u + `</a>.</p></body></html>` + "\n"
+}
+
+// outputFile maps a URL path to its file in the export: HTML pages become a
+// directory index, whatever their name (a slug may contain dots, as in
+// go-1.22-notes); feeds, CSS and the like keep their name.
+func outputFile(urlPath, contentType string) string {
+	p := strings.TrimPrefix(urlPath, "/")
+	if p != "" && !strings.HasPrefix(contentType, "text/html") {
+		return filepath.FromSlash(p)
+	}
+	return filepath.Join(filepath.FromSlash(p), "index.html")
+}
+
+func writeFile(name string, r io.Reader) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return 0, err
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(f, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return n, err
+}
+
+// copyBundle copies a page bundle's assets to dst, next to the post's index.html.
+func copyBundle(src fs.FS, dst string) (int, int64, error) {
+	var files int
+	var bytes int64
+	err := fs.WalkDir(src, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		f, err := src.Open(name)
+		if err != nil {
+			return err
+		}
+		b, err := writeFile(filepath.Join(dst, filepath.FromSlash(name)), f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		files++
+		bytes += b
+		return nil
+	})
+	return files, bytes, err
+}
+
+// copyFS copies the tree at dir in src to dst, returning files and bytes copied.
+func copyFS(src http.FileSystem, dir, dst string) (int, int64, error) {
+	d, err := src.Open(dir)
+	if err != nil {
+		return 0, 0, err
+	}
+	entries, err := d.Readdir(-1)
+	d.Close()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var files int
+	var bytes int64
+	for _, e := range entries {
+		name := path.Join(dir, e.Name())
+		if e.IsDir() {
+			n, b, err := copyFS(src, name, filepath.Join(dst, e.Name()))
+			if err != nil {
+				return files, bytes, err
+			}
+			files += n
+			bytes += b
+			continue
+		}
+		f, err := src.Open(name)
+		if err != nil {
+			return files, bytes, err
+		}
+		b, err := writeFile(filepath.Join(dst, e.Name()), f)
+		f.Close()
+		if err != nil {
+			return files, bytes, err
+		}
+		files++
+		bytes += b
+	}
+	return files, bytes, nil
+}
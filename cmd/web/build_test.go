@@ -0,0 +1,133 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuild_WritesPagesAndStatic(t *testing.T) {
+	app := mustBlogApp(t, map[string]string{
+		"a.md": "---\ntitle: Alpha\ndate: 2025-08-01\ntags: [web]\n---\na",
+		"b.md": "---\ntitle: Beta\ndate: 2025-08-02\naliases: [old-beta]\n---\nb",
+	})
+	app.cfg.Blog.PageSize = 1
+	app.cfg.Head.Styles = []string{"/static/css/site.css"}
+	out := t.TempDir()
+
+	stats, err := app.Build(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{
+		"index.html",
+		"blog/index.html",
+		"blog/page/2/index.html",
+		"blog/alpha/index.html",
+		"blog/beta/index.html",
+		"blog/old-beta/index.html",
+		"blog/tags/index.html",
+		"blog/archive/index.html",
+		"blog/2025/index.html",
+		"blog/2025/08/index.html",
+		"blog/tags/web/index.html",
+		"blog/tags/web/feed.atom",
+		"blog/feed.atom",
+		"blog/feed.xml",
+		"blog/feed.json",
+		"blog/syntax.css",
+		"blog/graph.json",
+		"sitemap.xml",
+		"robots.txt",
+		"404.html",
+		"500.html",
+		"static/css/site.css",
+	} {
+		if _, err := os.Stat(filepath.Join(out, f)); err != nil {
+			t.Fatalf("missing %s: %v", f, err)
+		}
+	}
+	if stats.Static != 2 || stats.Pages != 21 || stats.PageBytes == 0 {
+		t.Fatalf("stats = %+v", stats)
+	}
+
+	// Pages link the fingerprinted stylesheet, which is exported too.
+	css := app.assets.URL("/static/css/site.css")
+	b, _ := os.ReadFile(filepath.Join(out, "index.html"))
+	if !strings.Contains(string(b), `<link rel="stylesheet" href="`+css+`">`) {
+		t.Fatalf("home page does not link %s: %q", css, b)
+	}
+	if _, err := os.Stat(filepath.Join(out, filepath.FromSlash(strings.TrimPrefix(css, "/")))); err != nil {
+		t.Fatalf("fingerprinted stylesheet not exported: %v", err)
+	}
+
+	b, _ = os.ReadFile(filepath.Join(out, "blog/alpha/index.html"))
+	if !strings.Contains(string(b), "Alpha") {
+		t.Fatalf("post page not rendered: %q", b)
+	}
+	// Search needs the server: no form pointing at a page that isn't there.
+	b, _ = os.ReadFile(filepath.Join(out, "blog/index.html"))
+	if strings.Contains(string(b), "/blog/search") {
+		t.Fatalf("exported blog index links search: %q", b)
+	}
+	if _, body := get(t, app.Routes(), "/blog"); !strings.Contains(body, `action="/blog/search"`) {
+		t.Fatalf("served blog index lost its search form: %q", body)
+	}
+
+	b, _ = os.ReadFile(filepath.Join(out, "blog/old-beta/index.html"))
+	if !strings.Contains(string(b), `url=https://example.com/blog/beta"`) {
+		t.Fatalf("alias page does not redirect: %q", b)
+	}
+}
+
+func TestBuild_FailsOnBrokenPage(t *testing.T) {
+	app := mustBlogApp(t, map[string]string{
+		"a.md": "---\ntitle: Alpha\n---\na",
+	})
+	app.tpls = mustTestApp(t).tpls // no blog templates: every blog page 500s
+
+	_, err := app.Build(t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "/blog/alpha: status 500") {
+		t.Fatalf("err = %v, want failure naming /blog/alpha", err)
+	}
+}
+
+func TestBuild_CopiesBundleAssets(t *testing.T) {
+	app := mustBlogApp(t, bundlePosts)
+	out := t.TempDir()
+
+	stats, err := app.Build(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(out, "blog/trip/map.png"))
+	if err != nil || string(b) != "not really a png" {
+		t.Fatalf("bundle asset not copied: %q, %v", b, err)
+	}
+	for _, f := range []string{"blog/trip/index.md", "blog/trip/extra.md", "blog/trip/.secret"} {
+		if _, err := os.Stat(filepath.Join(out, f)); err == nil {
+			t.Fatalf("%s should not be exported", f)
+		}
+	}
+	if stats.Static != 3 { // site.css, its fingerprinted copy + map.png
+		t.Fatalf("static = %d, want 3", stats.Static)
+	}
+}
+
+func TestBuild_DottedSlugIsDirectory(t *testing.T) {
+	app := mustBlogApp(t, map[string]string{
+		"go-1.22-notes/index.md":  "---\ntitle: Go 1.22 notes\nslug: go-1.22-notes\n---\n![chart](chart.png)",
+		"go-1.22-notes/chart.png": "not really a png",
+	})
+	out := t.TempDir()
+
+	if _, err := app.Build(out); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"blog/go-1.22-notes/index.html", "blog/go-1.22-notes/chart.png", "blog/feed.atom"} {
+		if info, err := os.Stat(filepath.Join(out, f)); err != nil || info.IsDir() {
+			t.Fatalf("%s: %v, want a file", f, err)
+		}
+	}
+}
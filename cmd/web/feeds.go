@@ -0,0 +1,124 @@
+// cmd/web/feeds.go
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/brandondunbar/personal-site/internal/blog"
+	"github.com/brandondunbar/personal-site/internal/feed"
+)
+
+// feedLimit caps how many of the newest posts a feed carries.
+const feedLimit = 20
+
+type feedFormat struct {
+	path        string // feed path relative to its page, e.g. "feed.atom"
+	contentType string
+	write       func(io.Writer, feed.Feed) error
+}
+
+var (
+	feedAtom = feedFormat{"feed.atom", feed.AtomContentType, feed.WriteAtom}
+	feedRSS  = feedFormat{"feed.xml", feed.RSSContentType, feed.WriteRSS}
+	feedJSON = feedFormat{"feed.json", feed.JSONContentType, feed.WriteJSON}
+)
+
+// GET /blog/feed.atom, /blog/feed.xml, /blog/feed.json
+func (a *App) handleFeed(ff feedFormat) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f := a.buildFeed("/blog", "/blog/"+ff.path, a.cfg.Title, a.blog.All())
+		a.writeFeed(w, ff, f)
+	}
+}
+
+// GET /blog/tags/{tag}/feed.atom
+func (a *App) handleTagFeed(w http.ResponseWriter, r *http.Request) {
+	slug := blog.TagSlug(r.PathValue("tag"))
+	posts := a.blog.ByTag(slug)
+	if len(posts) == 0 {
+		a.renderNotFound(w, r)
+		return
+	}
+	page := "/blog/tags/" + slug
+	title := a.cfg.Title + " — #" + a.tagBySlug(slug, len(posts)).Name
+	a.writeFeed(w, feedAtom, a.buildFeed(page, page+"/"+feedAtom.path, title, posts))
+}
+
+func (a *App) writeFeed(w http.ResponseWriter, ff feedFormat, f feed.Feed) {
+	var buf bytes.Buffer
+	if err := ff.write(&buf, f); err != nil {
+		http.Error(w, "feed error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", ff.contentType)
+	_, _ = buf.WriteTo(w)
+}
+
+// urlAttrRE matches the URL attributes of rendered post HTML, which always
+// double-quotes them.
+var urlAttrRE = regexp.MustCompile(`(?i)(\s(?:href|src|srcset)\s*=\s*")([^"]*)"`)
+
+// absoluteURLs makes the root-relative URLs in post HTML (bundle images,
+// wiki links, image variants) absolute against base, for readers that show
+// the content away from the site.
+func absoluteURLs(html, base string) string {
+	abs := func(u string) string {
+		if strings.HasPrefix(u, "/") && !strings.HasPrefix(u, "//") {
+			return base + u
+		}
+		return u
+	}
+	return urlAttrRE.ReplaceAllStringFunc(html, func(m string) string {
+		sub := urlAttrRE.FindStringSubmatch(m)
+		if !strings.Contains(strings.ToLower(sub[1]), "srcset") {
+			return sub[1] + abs(sub[2]) + `"`
+		}
+		candidates := strings.Split(sub[2], ",")
+		for i, c := range candidates {
+			c = strings.TrimSpace(c)
+			u, descriptor, _ := strings.Cut(c, " ")
+			candidates[i] = strings.TrimSpace(abs(u) + " " + descriptor)
+		}
+		return sub[1] + strings.Join(candidates, ", ") + `"`
+	})
+}
+
+// buildFeed turns posts (newest first) into a feed with absolute links.
+// Updated is the newest post date so output only changes with content.
+func (a *App) buildFeed(pagePath, feedPath, title string, posts []blog.Post) feed.Feed {
+	base := a.rt.BaseURL
+	f := feed.Feed{
+		Title:       title,
+		Description: a.cfg.Head.MetaDescription,
+		Link:        base + pagePath,
+		FeedURL:     base + feedPath,
+		Author:      feed.Author{Name: a.cfg.Name, Email: a.cfg.Email, URL: base + "/"},
+	}
+	if len(posts) > feedLimit {
+		posts = posts[:feedLimit]
+	}
+	for _, p := range posts {
+		link := base + "/blog/" + p.Slug
+		f.Items = append(f.Items, feed.Item{
+			ID:        link,
+			Title:     p.Title,
+			Link:      link,
+			Summary:   p.Summary,
+			Content:   absoluteURLs(string(p.HTML), base),
+			Published: p.Date,
+			Updated:   p.Updated,
+			Tags:      p.Tags,
+		})
+		if p.LastMod().After(f.Updated) {
+			f.Updated = p.LastMod()
+		}
+	}
+	if f.Updated.IsZero() {
+		f.Updated = now()
+	}
+	return f
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandondunbar/personal-site/internal/blog"
+)
+
+func TestFeeds_ServeAllFormats(t *testing.T) {
+	app := mustBlogApp(t, tagPosts)
+
+	cases := []struct {
+		path, ct, want string
+	}{
+		{"/blog/feed.atom", "application/atom+xml", `<link href="https://example.com/blog/beta" rel="alternate" type="text/html"></link>`},
+		{"/blog/feed.xml", "application/rss+xml", `<link>https://example.com/blog/alpha</link>`},
+		{"/blog/feed.json", "application/feed+json", `"url": "https://example.com/blog/alpha"`},
+		{"/blog/tags/web/feed.atom", "application/atom+xml", `<id>https://example.com/blog/tags/web/feed.atom</id>`},
+	}
+	for _, c := range cases {
+		resp, body := get(t, app.Routes(), c.path)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: status = %d, want %d", c.path, resp.StatusCode, http.StatusOK)
+		}
+		if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, c.ct) {
+			t.Fatalf("%s: Content-Type = %q, want %s", c.path, ct, c.ct)
+		}
+		if !strings.Contains(body, c.want) {
+			t.Fatalf("%s: body missing %q:\n%s", c.path, c.want, body)
+		}
+	}
+}
+
+func TestFeeds_AbsoluteContentURLs(t *testing.T) {
+	app := mustBlogApp(t, map[string]string{
+		"trip/index.md": "---\ntitle: Trip\ndate: 2025-08-01\n---\n![map](map.png) after [[notes]], see [docs](https://go.dev/doc).",
+		"trip/map.png":  "not really a png",
+		"notes.md":      "---\ntitle: Notes\n---\nundated",
+	})
+
+	_, body := get(t, app.Routes(), "/blog/feed.json")
+	for _, want := range []string{
+		`src=\"https://example.com/blog/trip/map.png\"`,
+		`href=\"https://example.com/blog/notes\"`,
+		`href=\"https://go.dev/doc\"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("feed content missing %s:\n%s", want, body)
+		}
+	}
+
+	_, body = get(t, app.Routes(), "/blog/feed.atom")
+	if strings.Contains(body, "0001-01-01") {
+		t.Fatalf("undated post has a zero date:\n%s", body)
+	}
+}
+
+func TestAbsoluteURLs_Srcset(t *testing.T) {
+	got := absoluteURLs(`<img src="/a.png" srcset="/_img/a-1-320.png 320w, //cdn.example.com/a.png 640w, /a.png 800w">`, "https://example.com")
+	want := `<img src="https://example.com/a.png" srcset="https://example.com/_img/a-1-320.png 320w, //cdn.example.com/a.png 640w, https://example.com/a.png 800w">`
+	if got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestTagFeed_OnlyTaggedPosts(t *testing.T) {
+	app := mustBlogApp(t, tagPosts)
+
+	_, body := get(t, app.Routes(), "/blog/tags/web/feed.atom")
+	if strings.Contains(body, "/blog/beta") {
+		t.Fatalf("web feed contains untagged post:\n%s", body)
+	}
+	resp, _ := get(t, app.Routes(), "/blog/tags/rust/feed.atom")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("unknown tag feed status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestBlogPages_AdvertiseFeeds(t *testing.T) {
+	app := mustBlogApp(t, tagPosts)
+
+	_, body := get(t, app.Routes(), "/blog")
+	if !strings.Contains(body, `<link rel="alternate" type="application/atom+xml"`) {
+		t.Fatalf("blog index missing feed autodiscovery: %q", body)
+	}
+	_, body = get(t, app.Routes(), "/blog/tags/web")
+	if !strings.Contains(body, `href="/blog/tags/web/feed.atom"`) {
+		t.Fatalf("tag page missing tag feed link: %q", body)
+	}
+}
+
+func TestFeedsAndSitemap_FollowSchedule(t *testing.T) {
+	app := mustBlogApp(t, nil)
+	td := t.TempDir()
+	if err := os.WriteFile(filepath.Join(td, "soon.md"), []byte("---\ntitle: Soon\ndate: 2025-08-01T12:00:00Z\n---\nsoon"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	clock := time.Date(2025, 8, 1, 11, 0, 0, 0, time.UTC)
+	bs, err := blog.NewFilesStore(td, blog.WithNow(func() time.Time { return clock }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.blog = bs
+	h := app.Routes()
+
+	for _, path := range []string{"/blog/feed.atom", "/blog/feed.json", "/sitemap.xml"} {
+		if _, body := get(t, h, path); strings.Contains(body, "/blog/soon") {
+			t.Fatalf("%s lists the post before its date", path)
+		}
+	}
+	clock = clock.Add(time.Hour)
+	for _, path := range []string{"/blog/feed.atom", "/blog/feed.json", "/sitemap.xml"} {
+		if _, body := get(t, h, path); !strings.Contains(body, "/blog/soon") {
+			t.Fatalf("%s missing the post once published", path)
+		}
+	}
+}
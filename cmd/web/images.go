@@ -0,0 +1,63 @@
+// cmd/web/images.go
+package main
+
+import (
+	"os"
+
+	"github.com/brandondunbar/personal-site/internal/config"
+	"github.com/brandondunbar/personal-site/internal/images"
+)
+
+// imagePrefix is where resized image variants are served (see
+// internal/images); Build exports them to the same path.
+const imagePrefix = "/static/_img/"
+
+// postImageSizes is the sizes attribute of post images: the article column
+// is the container (min(1100px, 92%)) less 2rem of padding on each side.
+const postImageSizes = "(min-width: 1200px) 1036px, calc(92vw - 4rem)"
+
+// newImages returns the image processor for site and post images. Variants
+// are cached in IMAGE_CACHE_DIR, by default .cache/images in the repo.
+func newImages() (*images.Processor, error) {
+	dir := os.Getenv("IMAGE_CACHE_DIR")
+	if dir == "" {
+		dir = templatePath(".cache/images")
+	}
+	return images.New(dir, imagePrefix,
+		images.WithSource("/static/", os.DirFS(templatePath("web/static"))),
+		images.WithSizes(postImageSizes),
+	)
+}
+
+// projectImages fills in the Thumbnail and Gallery of every project from
+// its Thumb and Images. Images outside web/static, or that aren't raster,
+// are kept as they are.
+func projectImages(p *images.Processor, work *config.Work) error {
+	for i := range work.Projects {
+		proj := &work.Projects[i]
+		proj.Thumbnail, proj.Gallery = nil, nil
+		if proj.Thumb != "" {
+			pic, err := picture(p, proj.Thumb)
+			if err != nil {
+				return err
+			}
+			proj.Thumbnail = &pic
+		}
+		for _, src := range proj.Images {
+			pic, err := picture(p, src)
+			if err != nil {
+				return err
+			}
+			proj.Gallery = append(proj.Gallery, pic)
+		}
+	}
+	return nil
+}
+
+func picture(p *images.Processor, src string) (config.Picture, error) {
+	im, ok, err := p.Resolve(src)
+	if err != nil || !ok {
+		return config.Picture{Src: src}, err
+	}
+	return config.Picture{Src: src, Srcset: im.Srcset(), Width: im.Width, Height: im.Height}, nil
+}
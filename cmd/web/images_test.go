@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brandondunbar/personal-site/internal/config"
+	"github.com/brandondunbar/personal-site/internal/images"
+)
+
+func TestProjectImages_ResponsiveCards(t *testing.T) {
+	static := t.TempDir()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 800, 400))); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(static, "img"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(static, "img", "shot.png"), buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	imgs, err := images.New(t.TempDir(), imagePrefix,
+		images.WithWidths(400),
+		images.WithSource("/static/", os.DirFS(static)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	work := config.Work{Projects: []config.Project{{
+		Title:  "Virus Removal",
+		Thumb:  "/static/img/shot.png",
+		Images: []string{"/static/img/shot.png", "https://example.com/x.png"},
+	}}}
+	if err := projectImages(imgs, &work); err != nil {
+		t.Fatal(err)
+	}
+	proj := work.Projects[0]
+	if proj.Thumbnail == nil || proj.Thumbnail.Width != 800 || proj.Thumbnail.Srcset == "" ||
+		len(proj.Gallery) != 2 || proj.Gallery[0].Width != 800 || proj.Gallery[1].Srcset != "" {
+		t.Fatalf("project = %+v", proj)
+	}
+	variant, _, _ := strings.Cut(proj.Gallery[0].Srcset, " ")
+
+	tpls, err := loadTemplates(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := tpls.ExecuteTemplate(&out, "partials/project-cards", work); err != nil {
+		t.Fatal(err)
+	}
+	html := out.String()
+	for _, want := range []string{
+		`<img src="/static/img/shot.png" srcset="` + variant + ` 400w, /static/img/shot.png 800w" sizes="(min-width: 40rem) 16rem, 50vw" width="800" height="400" alt="image: Virus Removal screenshot"`,
+		`<img src="https://example.com/x.png" alt="image: Virus Removal screenshot"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("missing %q in:\n%s", want, html)
+		}
+	}
+
+	app := mustBlogApp(t, nil)
+	app.images = imgs
+	resp, body := get(t, app.Routes(), variant)
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(body, "\x89PNG") {
+		t.Fatalf("variant %s: status %d", variant, resp.StatusCode)
+	}
+	if cc := resp.Header.Get("Cache-Control"); !strings.Contains(cc, "immutable") {
+		t.Fatalf("Cache-Control = %q", cc)
+	}
+
+	// The build exports the variants the home page uses, and no others.
+	app.cfg.Work = work
+	stale := filepath.Join(imgs.Dir(), "old-0123456789ab-400.png")
+	if err := os.WriteFile(stale, []byte("stale"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	site := t.TempDir()
+	if _, err := app.Build(site); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(site, filepath.FromSlash(strings.TrimPrefix(variant, "/")))); err != nil {
+		t.Fatalf("variant not exported: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(site, "static", "_img", filepath.Base(stale))); err == nil {
+		t.Fatal("unused variant exported")
+	}
+}
@@ -2,7 +2,9 @@
 package main
 
 import (
+	"fmt"
 	"net/http"
+	"os"
 
 	"github.com/brandondunbar/personal-site/internal/config"
 )
@@ -10,10 +12,28 @@ import (
 func main() {
 	rt := config.LoadRuntime()
 
+	// Subcommands; no argument serves the site.
+	if len(os.Args) > 1 {
+		var err error
+		switch os.Args[1] {
+		case "build":
+			err = runBuild(rt, os.Args[2:])
+		case "preview-token":
+			err = runPreviewToken(rt, os.Args[2:])
+		default:
+			err = fmt.Errorf("unknown command %q (want: build, preview-token)", os.Args[1])
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	println("Server environment:", rt.Env)
 	println("Server listening on", rt.BaseURL)
 
-	app, err := NewApp()
+	app, err := NewApp(rt)
 	if err != nil {
 		panic(err)
 	}
@@ -22,4 +42,3 @@ func main() {
 		panic(err)
 	}
 }
-
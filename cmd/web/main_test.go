@@ -4,6 +4,7 @@ package main
 import (
 	"html/template"
 	"io"
+	"io/fs"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -15,6 +16,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/brandondunbar/personal-site/internal/assets"
 	"github.com/brandondunbar/personal-site/internal/blog"
 	"github.com/brandondunbar/personal-site/internal/config"
 )
@@ -44,20 +46,29 @@ func TestStaticServesFile_WithCacheControl(t *testing.T) {
 	srv := httptest.NewServer(app.Routes())
 	defer srv.Close()
 
-	// File created by mustTestApp at /static/css/site.css
-	resp, err := http.Get(srv.URL + "/static/css/site.css")
-	if err != nil {
-		t.Fatalf("GET /static/css/site.css: %v", err)
-	}
-	defer resp.Body.Close()
+	// File created by mustTestApp at /static/css/site.css: its fingerprinted
+	// URL is cached for good, the plain one must revalidate.
+	fingerprinted := app.assets.URL("/static/css/site.css")
+	if fingerprinted == "/static/css/site.css" {
+		t.Fatal("site.css has no fingerprinted URL")
+	}
+	for path, wantCC := range map[string]string{
+		fingerprinted:          "public, max-age=31536000, immutable",
+		"/static/css/site.css": "public, no-cache",
+	} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
-	}
-	gotCC := resp.Header.Get("Cache-Control")
-	wantCC := "public, max-age=31536000, immutable"
-	if gotCC != wantCC {
-		t.Fatalf("Cache-Control = %q, want %q", gotCC, wantCC)
+		if resp.StatusCode != http.StatusOK || string(body) != "/* test */" {
+			t.Fatalf("GET %s: status = %d, body %q", path, resp.StatusCode, body)
+		}
+		if gotCC := resp.Header.Get("Cache-Control"); gotCC != wantCC {
+			t.Fatalf("GET %s: Cache-Control = %q, want %q", path, gotCC, wantCC)
+		}
 	}
 }
 
@@ -66,6 +77,9 @@ func TestStaticServesFile_WithCacheControl(t *testing.T) {
 type fakeBlog struct{ posts []blog.Post }
 
 func (f fakeBlog) All() []blog.Post { return append([]blog.Post(nil), f.posts...) }
+func (f fakeBlog) Page(page, size int) ([]blog.Post, int) {
+	return f.All(), len(f.posts)
+}
 func (f fakeBlog) BySlug(s string) (blog.Post, bool) {
 	for _, p := range f.posts {
 		if p.Slug == s {
@@ -74,7 +88,17 @@ func (f fakeBlog) BySlug(s string) (blog.Post, bool) {
 	}
 	return blog.Post{}, false
 }
-func (f fakeBlog) ByTag(tag string) []blog.Post { return nil }
+func (f fakeBlog) Alias(slug string) (string, bool)           { return "", false }
+func (f fakeBlog) Preview(slug string) (blog.Post, bool)       { return blog.Post{}, false }
+func (f fakeBlog) BundleFS(slug string) (fs.FS, bool)         { return nil, false }
+func (f fakeBlog) ByTag(tag string) []blog.Post               { return nil }
+func (f fakeBlog) Tags() []blog.Tag                           { return nil }
+func (f fakeBlog) Search(q string, n int) []blog.SearchResult { return nil }
+func (f fakeBlog) Series(name string) []blog.Post             { return nil }
+func (f fakeBlog) Related(slug string, n int) []blog.Post     { return nil }
+func (f fakeBlog) Archive() blog.Archive                      { return blog.Archive{} }
+func (f fakeBlog) ByDate(y int, m time.Month) []blog.Post     { return nil }
+func (f fakeBlog) Graph() blog.Graph                          { return blog.Graph{} }
 
 func TestHome_Renders_WithConfigAndYear(t *testing.T) {
 	app := mustTestApp(t)
@@ -321,7 +345,7 @@ func mustTestApp(t *testing.T) *App {
 
 	// Layout + home content
 	const baseTpl = `{{define "base"}}<html><head><title>{{block "title" .}}x{{end}}</title></head><body>{{block "content" .}}{{end}}</body></html>{{end}}`
-	const homeTpl = `{{define "title"}}Home - {{.Site.Name}}{{end}}{{define "content"}}Hello {{.Site.Email}} — {{.Year}}{{end}}`
+	const homeTpl = `{{define "home"}}{{template "base" .}}{{end}}{{define "title"}}Home - {{.Site.Name}}{{end}}{{define "content"}}Hello {{.Site.Email}} — {{.Year}}{{end}}`
 	// Custom 404 page used by renderNotFound (template name "notfound")
 	const notFoundTpl = `{{define "notfound"}}<!doctype html><title>Not Found</title><h1>Custom 404</h1>{{end}}`
 
@@ -339,18 +363,25 @@ func mustTestApp(t *testing.T) *App {
 		t.Fatalf("write css: %v", err)
 	}
 
+	manifest, err := assets.New(os.DirFS(td), "/static/")
+	if err != nil {
+		t.Fatalf("assets: %v", err)
+	}
+
 	// Quiet logger for tests
 	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
 
 	return &App{
 		tpls:     tpls,
 		staticFS: http.Dir(td),
+		assets:   manifest,
 		cfg: config.Config{
 			Name:    "Elliot Alderson",
 			Email:   "name@domain.com",
 			Brand:   "mr.robot",
 			Tagline: "Computer Repair with a Smile",
 		},
+		rt:  config.Runtime{Env: "dev", BaseURL: "https://example.com"},
 		log: logger,
 		blog: fakeBlog{posts: []blog.Post{
 			{Title: "Hello", Slug: "hello", Date: time.Now(), HTML: template.HTML("Hi")},
@@ -358,9 +389,46 @@ func mustTestApp(t *testing.T) *App {
 	}
 }
 
+// helper: mustBlogApp serves the real templates over a FilesStore built from
+// the given Markdown files (name -> contents).
+func mustBlogApp(t *testing.T, files map[string]string) *App {
+	t.Helper()
+
+	app := mustTestApp(t)
+	tpls, err := loadTemplates(app.assets)
+	if err != nil {
+		t.Fatalf("templates: %v", err)
+	}
+	td := t.TempDir()
+	for name, body := range files {
+		path := filepath.Join(td, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	bs, err := blog.NewFilesStore(td, blog.WithShortcodes(tpls, shortcodeSite{app.cfg}))
+	if err != nil {
+		t.Fatalf("blog store: %v", err)
+	}
+
+	app.tpls = tpls
+	app.blog = bs
+	return app
+}
+
+// helper: get performs a GET without following redirects.
+func get(t *testing.T, h http.Handler, path string) (*http.Response, string) {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+	return rr.Result(), rr.Body.String()
+}
+
 func ioReadAll(r io.Reader) (string, error) {
 	var sb strings.Builder
 	_, err := io.Copy(&sb, r)
 	return sb.String(), err
 }
-
@@ -0,0 +1,117 @@
+// cmd/web/preview.go
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/brandondunbar/personal-site/internal/blog"
+	"github.com/brandondunbar/personal-site/internal/config"
+	"github.com/brandondunbar/personal-site/internal/preview"
+)
+
+/*
+Signed previews of unpublished posts.
+
+	web preview-token -ttl 48h my-draft
+	-> https://example.com/blog/my-draft?preview=<token>
+
+The link renders the draft (or scheduled post) with a banner and noindex.
+It also sets a cookie scoped to /blog/{slug}/ so the post's bundle assets
+load. Previews are off unless PREVIEW_SECRET is set.
+*/
+
+// previewCookie carries a verified token to bundle asset requests.
+const previewCookie = "blog_preview"
+
+// defaultPreviewTTL is how long minted links stay valid.
+const defaultPreviewTTL = 72 * time.Hour
+
+// previewSigner reads PREVIEW_SECRET; nil disables previews.
+func previewSigner() *preview.Signer {
+	secret := os.Getenv("PREVIEW_SECRET")
+	if secret == "" {
+		return nil
+	}
+	return preview.NewSigner([]byte(secret))
+}
+
+// runPreviewToken implements the "preview-token" subcommand.
+func runPreviewToken(rt config.Runtime, args []string) error {
+	fs := flag.NewFlagSet("preview-token", flag.ContinueOnError)
+	ttl := fs.Duration("ttl", defaultPreviewTTL, "how long the link stays valid")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: web preview-token [-ttl 72h] <slug>")
+	}
+	signer := previewSigner()
+	if signer == nil {
+		return errors.New("preview-token: PREVIEW_SECRET is not set")
+	}
+	if *ttl <= 0 {
+		return errors.New("preview-token: -ttl must be positive")
+	}
+	slug := fs.Arg(0)
+	fmt.Println(previewURL(rt.BaseURL, slug, signer.Token(slug, *ttl)))
+	return nil
+}
+
+func previewURL(base, slug, token string) string {
+	return base + "/blog/" + url.PathEscape(slug) + "?preview=" + url.QueryEscape(token)
+}
+
+// previewPost returns the unpublished post for a request carrying a valid
+// ?preview= token, and sets the asset cookie. Bad or expired tokens look
+// exactly like a missing post.
+func (a *App) previewPost(w http.ResponseWriter, r *http.Request, slug string) (blog.Post, bool) {
+	token := r.URL.Query().Get("preview")
+	if a.preview == nil || token == "" {
+		return blog.Post{}, false
+	}
+	exp, err := a.preview.Verify(slug, token)
+	if err != nil {
+		return blog.Post{}, false
+	}
+	post, ok := a.blog.Preview(slug)
+	if !ok {
+		return blog.Post{}, false
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     previewCookie,
+		Value:    token,
+		Path:     "/blog/" + slug + "/",
+		Expires:  exp,
+		HttpOnly: true,
+		Secure:   a.rt.Env == "prod",
+		SameSite: http.SameSiteLaxMode,
+	})
+	setPreviewHeaders(w)
+	return post, true
+}
+
+// canPreviewAssets reports whether r holds a valid preview cookie for slug.
+func (a *App) canPreviewAssets(r *http.Request, slug string) bool {
+	if a.preview == nil {
+		return false
+	}
+	c, err := r.Cookie(previewCookie)
+	if err != nil {
+		return false
+	}
+	_, err = a.preview.Verify(slug, c.Value)
+	return err == nil
+}
+
+// setPreviewHeaders keeps previews out of indexes, caches and Referer headers.
+func setPreviewHeaders(w http.ResponseWriter) {
+	w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brandondunbar/personal-site/internal/preview"
+)
+
+func mustPreviewApp(t *testing.T) *App {
+	t.Helper()
+	app := mustBlogApp(t, map[string]string{
+		"live.md":        "---\ntitle: Live\ndate: 2025-08-01\n---\nlive",
+		"draft/index.md": "---\ntitle: Draft\ndraft: true\n---\n![pic](pic.png)",
+		"draft/pic.png":  "png",
+	})
+	app.preview = preview.NewSigner([]byte("test-secret"))
+	return app
+}
+
+func TestPreview_RendersDraftWithValidToken(t *testing.T) {
+	app := mustPreviewApp(t)
+	h := app.Routes()
+
+	if resp, _ := get(t, h, "/blog/draft"); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("draft without token: status %d, want 404", resp.StatusCode)
+	}
+
+	tok := app.preview.Token("draft", time.Hour)
+	resp, body := get(t, h, "/blog/draft?preview="+tok)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if !strings.Contains(body, `class="preview-banner"`) || !strings.Contains(body, `<meta name="robots" content="noindex, nofollow">`) {
+		t.Fatalf("preview missing banner or noindex: %q", body)
+	}
+	if got := resp.Header.Get("X-Robots-Tag"); got != "noindex, nofollow" {
+		t.Fatalf("X-Robots-Tag = %q", got)
+	}
+
+	var cookie *http.Cookie
+	for _, c := range resp.Cookies() {
+		if c.Name == previewCookie {
+			cookie = c
+		}
+	}
+	if cookie == nil || cookie.Path != "/blog/draft/" {
+		t.Fatalf("preview cookie = %+v", cookie)
+	}
+
+	// Bundle assets need the cookie.
+	if resp, _ := get(t, h, "/blog/draft/pic.png"); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("asset without cookie: status %d, want 404", resp.StatusCode)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/blog/draft/pic.png", nil)
+	req.AddCookie(cookie)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || rr.Body.String() != "png" {
+		t.Fatalf("asset with cookie: status %d body %q", rr.Code, rr.Body.String())
+	}
+}
+
+func TestPreview_RejectsBadTokens(t *testing.T) {
+	app := mustPreviewApp(t)
+	h := app.Routes()
+
+	for _, tok := range []string{
+		"garbage",
+		app.preview.Token("live", time.Hour), // minted for another slug
+		app.preview.Token("draft", -time.Minute),
+	} {
+		if resp, _ := get(t, h, "/blog/draft?preview="+tok); resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("token %q: status %d, want 404", tok, resp.StatusCode)
+		}
+	}
+
+	// Without a secret previews are off entirely.
+	tok := app.preview.Token("draft", time.Hour)
+	app.preview = nil
+	if resp, _ := get(t, h, "/blog/draft?preview="+tok); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("no signer: status %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestPreviewURL(t *testing.T) {
+	got := previewURL("https://example.com", "my-draft", "abc.d_e")
+	if got != "https://example.com/blog/my-draft?preview=abc.d_e" {
+		t.Fatalf("previewURL = %q", got)
+	}
+}
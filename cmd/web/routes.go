@@ -5,9 +5,7 @@ import (
 	"bytes"
 	"fmt"
 	"net/http"
-	"strings"
 
-	"github.com/brandondunbar/personal-site/internal/blog"
 	"github.com/brandondunbar/personal-site/internal/httpx"
 )
 
@@ -29,56 +27,34 @@ func (a *App) Routes() http.Handler {
           panic("boom")
         })
 
-	// Static assets with long cache
+	// Crawlers
+	mux.HandleFunc("/sitemap.xml", a.handleSitemap)
+	mux.HandleFunc("/robots.txt", a.handleRobots)
+
+	// Static assets: fingerprinted URLs are cached for good, plain ones revalidate
 	fs := http.FileServer(a.staticFS)
-	mux.Handle("/static/", cacheControl(http.StripPrefix("/static/", fs)))
-
-	// Blog index
-	mux.HandleFunc("/blog", func(w http.ResponseWriter, r *http.Request) {
-		posts := a.blog.All()
-		data := struct {
-			TemplateData
-			Posts []blog.Post
-		}{
-			TemplateData: TemplateData{Site: a.cfg, Year: now().Year()},
-			Posts:        posts,
-		}
-		var buf bytes.Buffer
-		if err := a.tpls.ExecuteTemplate(&buf, "blog_index", data); err != nil {
-			http.Error(w, "template error: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
-		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		_, _ = buf.WriteTo(w)
-	})
+	mux.Handle("/static/", a.assets.Handler(http.StripPrefix("/static/", fs)))
+	if a.images != nil {
+		variants := http.FileServer(http.Dir(a.images.Dir()))
+		mux.Handle(imagePrefix, cacheControl(http.StripPrefix(imagePrefix, variants)))
+	}
 
-	// Blog detail /blog/{slug}
-	mux.HandleFunc("/blog/", func(w http.ResponseWriter, r *http.Request) {
-		slug := strings.TrimPrefix(r.URL.Path, "/blog/")
-		if slug == "" || strings.Contains(slug, "/") {
-			a.renderNotFound(w, r)
-			return
-		}
-		post, ok := a.blog.BySlug(slug)
-		if !ok {
-			a.renderNotFound(w, r)
-			return
-		}
-		data := struct {
-			TemplateData
-			Post blog.Post
-		}{
-			TemplateData: TemplateData{Site: a.cfg, Year: now().Year()},
-			Post:         post,
-		}
-		var buf bytes.Buffer
-		if err := a.tpls.ExecuteTemplate(&buf, "blog_post", data); err != nil {
-			http.Error(w, "template error: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
-		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		_, _ = buf.WriteTo(w)
-	})
+	// Blog
+	mux.HandleFunc("/blog", a.handleBlogIndex)
+	mux.HandleFunc("/blog/page/{n}", a.handleBlogIndex)
+	mux.HandleFunc("/blog/search", a.handleSearch)
+	mux.HandleFunc("/blog/syntax.css", a.handleSyntaxCSS)
+	mux.HandleFunc("/blog/graph.json", a.handleGraph)
+	mux.HandleFunc("/blog/tags", a.handleTags)
+	mux.HandleFunc("/blog/tags/{tag}", a.handleTag)
+	mux.HandleFunc("/blog/series/{name}", a.handleSeries)
+	mux.HandleFunc("/blog/archive", a.handleArchive)
+	mux.HandleFunc("/blog/archive/undated", a.handleUndated)
+	mux.HandleFunc("/blog/feed.atom", a.handleFeed(feedAtom))
+	mux.HandleFunc("/blog/feed.xml", a.handleFeed(feedRSS))
+	mux.HandleFunc("/blog/feed.json", a.handleFeed(feedJSON))
+	mux.HandleFunc("/blog/tags/{tag}/feed.atom", a.handleTagFeed)
+	mux.HandleFunc("/blog/", a.handleBlogPost) // /blog/{slug}, /blog/{year}[/{month}]
 
 	// Home — only for "/"
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -87,13 +63,7 @@ func (a *App) Routes() http.Handler {
 			return
 		}
 		data := TemplateData{Site: a.cfg, Year: now().Year(), Title: "Home | " + a.cfg.Title} 
-		var buf bytes.Buffer
-		if err := a.tpls.ExecuteTemplate(&buf, "home", data); err != nil {
-			http.Error(w, "template error: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
-		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		_, _ = buf.WriteTo(w)
+		a.render(w, "home", data)
 	})
 
 	// Middleware chain: RequestID -> Recover -> Logger
@@ -103,6 +73,18 @@ func (a *App) Routes() http.Handler {
 	return h
 }
 
+// render executes the named template into a buffer so a template failure
+// can still produce a clean 500 instead of a half-written page.
+func (a *App) render(w http.ResponseWriter, name string, data any) {
+	var buf bytes.Buffer
+	if err := a.tpls.ExecuteTemplate(&buf, name, data); err != nil {
+		http.Error(w, "template error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = buf.WriteTo(w)
+}
+
 // renderNotFound renders a custom 404 page if template "notfound" exists; otherwise a tiny HTML fallback.
 func (a *App) renderNotFound(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -0,0 +1,30 @@
+// cmd/web/shortcodes.go
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brandondunbar/personal-site/internal/config"
+)
+
+// shortcodeGlob matches the blog shortcode templates; each defines
+// "shortcodes/<name>" (see blog.WithShortcodes).
+const shortcodeGlob = "web/templates/shortcodes/*.html.tmpl"
+
+// shortcodeSite is .Site in shortcode templates: the site config plus
+// lookups that fail the render when a post names something that isn't there.
+type shortcodeSite struct {
+	config.Config
+}
+
+// Project returns the Work project with the given title, for
+// {{< project "Title" >}}.
+func (s shortcodeSite) Project(title string) (config.Project, error) {
+	for _, p := range s.Work.Projects {
+		if strings.EqualFold(p.Title, strings.TrimSpace(title)) {
+			return p, nil
+		}
+	}
+	return config.Project{}, fmt.Errorf("no project %q in Work.Projects", title)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brandondunbar/personal-site/internal/blog"
+	"github.com/brandondunbar/personal-site/internal/config"
+)
+
+func shortcodePost(t *testing.T, cfg config.Config, body string) (blog.Post, error) {
+	t.Helper()
+	tpls, err := loadTemplates(nil)
+	if err != nil {
+		t.Fatalf("templates: %v", err)
+	}
+	td := t.TempDir()
+	if err := os.WriteFile(filepath.Join(td, "post.md"), []byte("---\ntitle: Post\n---\n"+body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	bs, err := blog.NewFilesStore(td, blog.WithShortcodes(tpls, shortcodeSite{cfg}))
+	if err != nil {
+		return blog.Post{}, err
+	}
+	p, _ := bs.BySlug("post")
+	return p, nil
+}
+
+func TestShortcodes_SiteTemplates(t *testing.T) {
+	var cfg config.Config
+	cfg.Work.Projects = []config.Project{{
+		Title: "Virus Removal",
+		Blurb: "We got those buggers.",
+		Tech:  []string{"Python"},
+		Links: []config.ProjectLink{{Href: "https://example.com/vr", Label: "Write-up"}},
+	}}
+	p, err := shortcodePost(t, cfg, `{{< callout warning title="Heads up" >}}
+Back up **first**.
+{{< /callout >}}
+
+{{< figure src="https://example.com/a.png" caption="A <cat>" >}}
+
+{{< gist elliot abc123 notes.md >}}
+
+{{< project "virus removal" >}}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(p.HTML)
+	for _, want := range []string{
+		`<aside class="callout callout--warning" role="note">`,
+		`<p class="callout__title">Heads up</p>`,
+		`<div class="callout__body"><p>Back up <strong>first</strong>.</p>`,
+		`<img src="https://example.com/a.png" alt="A &lt;cat&gt;" loading="lazy"`,
+		`<figcaption>A &lt;cat&gt;</figcaption>`,
+		`href="https://gist.github.com/elliot/abc123"`,
+		`<span class="embed__title">elliot/notes.md</span>`,
+		`<h3 class="project-title">Virus Removal</h3>`,
+		`<a href="https://example.com/vr" class="btn btn--tiny">Write-up</a>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("missing %q in:\n%s", want, html)
+		}
+	}
+}
+
+func TestShortcodes_UnknownProjectFails(t *testing.T) {
+	_, err := shortcodePost(t, config.Config{}, "\n{{< project \"Nope\" >}}\n")
+	if err == nil || !strings.Contains(err.Error(), "shortcode post.md:5:") || !strings.Contains(err.Error(), `no project "Nope"`) {
+		t.Fatalf("err=%v", err)
+	}
+}
@@ -0,0 +1,105 @@
+// cmd/web/sitemap.go
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type urlset struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+// GET /sitemap.xml
+// Lists the home page, the blog index, every post and every tag page.
+func (a *App) handleSitemap(w http.ResponseWriter, r *http.Request) {
+	base := a.rt.BaseURL
+	lastmod := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format("2006-01-02")
+	}
+
+	posts := a.blog.All()
+	var newest time.Time
+	for _, p := range posts {
+		if p.LastMod().After(newest) {
+			newest = p.LastMod()
+		}
+	}
+
+	set := urlset{URLs: []sitemapURL{
+		{Loc: base + "/"},
+		{Loc: base + "/blog", LastMod: lastmod(newest)},
+	}}
+	for _, p := range posts {
+		if p.Canonical != "" {
+			continue // cross-posted: the original is the indexable copy
+		}
+		set.URLs = append(set.URLs, sitemapURL{Loc: base + "/blog/" + p.Slug, LastMod: lastmod(p.LastMod())})
+	}
+	if tags := a.blog.Tags(); len(tags) > 0 {
+		set.URLs = append(set.URLs, sitemapURL{Loc: base + "/blog/tags", LastMod: lastmod(newest)})
+		for _, t := range tags {
+			var tagNewest time.Time
+			for _, p := range a.blog.ByTag(t.Slug) {
+				if p.LastMod().After(tagNewest) {
+					tagNewest = p.LastMod()
+				}
+			}
+			set.URLs = append(set.URLs, sitemapURL{Loc: base + "/blog/tags/" + t.Slug, LastMod: lastmod(tagNewest)})
+		}
+	}
+	for _, slug := range seriesSlugs(posts) {
+		var seriesNewest time.Time
+		for _, p := range a.blog.Series(slug) {
+			if p.LastMod().After(seriesNewest) {
+				seriesNewest = p.LastMod()
+			}
+		}
+		set.URLs = append(set.URLs, sitemapURL{Loc: base + "/blog/series/" + slug, LastMod: lastmod(seriesNewest)})
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(set); err != nil {
+		http.Error(w, "sitemap error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	buf.WriteByte('\n')
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	_, _ = buf.WriteTo(w)
+}
+
+// GET /robots.txt
+// Outside prod everything is disallowed so previews never get indexed.
+func (a *App) handleRobots(w http.ResponseWriter, r *http.Request) {
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	if a.rt.Env != "prod" {
+		b.WriteString("Disallow: /\n")
+	} else {
+		b.WriteString("Disallow: /_test/\n")
+		for _, p := range a.cfg.Robots.Disallow {
+			b.WriteString("Disallow: " + p + "\n")
+		}
+		for _, p := range a.cfg.Robots.Allow {
+			b.WriteString("Allow: " + p + "\n")
+		}
+		b.WriteString("\nSitemap: " + a.rt.BaseURL + "/sitemap.xml\n")
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte(b.String()))
+}
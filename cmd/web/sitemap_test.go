@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSitemap_ListsPagesPostsAndTags(t *testing.T) {
+	app := mustBlogApp(t, map[string]string{
+		"a.md": "---\ntitle: Alpha\ndate: 2025-08-01\nupdated: 2025-09-10\ntags: [web]\n---\na",
+		"b.md": "---\ntitle: Beta\ndate: 2025-08-02\n---\nb",
+	})
+
+	resp, body := get(t, app.Routes(), "/sitemap.xml")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/xml") {
+		t.Fatalf("Content-Type = %q, want application/xml", ct)
+	}
+	for _, want := range []string{
+		"<loc>https://example.com/</loc>",
+		"<loc>https://example.com/blog</loc>",
+		"<loc>https://example.com/blog/alpha</loc>\n    <lastmod>2025-09-10</lastmod>",
+		"<loc>https://example.com/blog/beta</loc>\n    <lastmod>2025-08-02</lastmod>",
+		"<loc>https://example.com/blog/tags/web</loc>\n    <lastmod>2025-09-10</lastmod>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("sitemap missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestRobots_ByEnv(t *testing.T) {
+	app := mustTestApp(t)
+	app.cfg.Robots.Disallow = []string{"/drafts/"}
+
+	_, body := get(t, app.Routes(), "/robots.txt")
+	if body != "User-agent: *\nDisallow: /\n" {
+		t.Fatalf("dev robots = %q, want fully blocking", body)
+	}
+
+	app.rt.Env = "prod"
+	_, body = get(t, app.Routes(), "/robots.txt")
+	for _, want := range []string{"Disallow: /_test/\n", "Disallow: /drafts/\n", "Sitemap: https://example.com/sitemap.xml\n"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("prod robots missing %q: %q", want, body)
+		}
+	}
+	if strings.Contains(body, "Disallow: /\n") {
+		t.Fatalf("prod robots blocks everything: %q", body)
+	}
+}
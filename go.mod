@@ -3,6 +3,11 @@ module github.com/brandondunbar/personal-site
 go 1.24.6
 
 require (
+	github.com/alecthomas/chroma/v2 v2.20.0
+	github.com/pelletier/go-toml/v2 v2.2.4
 	github.com/yuin/goldmark v1.7.13
+	golang.org/x/image v0.25.0
 	gopkg.in/yaml.v3 v3.0.1
 )
+
+require github.com/dlclark/regexp2 v1.11.5 // indirect
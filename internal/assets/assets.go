@@ -0,0 +1,153 @@
+// internal/assets/assets.go
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+/*
+Content-hash fingerprinted static assets.
+
+A Manifest hashes every file of the static directory once, at startup, so
+pages can link to
+
+	/static/css/01-base.3f2a9c1e7b.css
+
+instead of /static/css/01-base.css. The fingerprinted name changes whenever
+the content does, which makes it safe to cache for a year; a deploy that
+changes a file changes its URL. Plain names keep working but are served
+with a cache that must revalidate, so nothing stale outlives a deploy.
+
+Only URLs that go through Manifest.URL get a fingerprint. Files reached by
+a relative path from another file, such as the ES modules main.js imports,
+keep their plain names and the revalidating cache. Edits made while the
+server runs show up under the old fingerprint until a restart rehashes.
+*/
+
+const (
+	// ImmutableCache is sent with fingerprinted assets.
+	ImmutableCache = "public, max-age=31536000, immutable"
+	// RevalidateCache is sent with plain names: cache, but check first.
+	RevalidateCache = "public, no-cache"
+)
+
+// hashLen is how many hex digits of the SHA-256 go into a fingerprint.
+const hashLen = 10
+
+// Manifest maps static file URLs to their fingerprinted form. A nil
+// *Manifest fingerprints nothing.
+type Manifest struct {
+	prefix string            // URL path the directory is served at, e.g. "/static/"
+	byName map[string]string // "css/site.css" -> "css/site.3f2a9c1e7b.css"
+	byHash map[string]string // the reverse
+	etags  map[string]string // plain name -> quoted content hash
+}
+
+// New hashes every file in fsys, which is served at the URL path prefix.
+// Files and directories starting with "." are skipped.
+func New(fsys fs.FS, prefix string) (*Manifest, error) {
+	m := &Manifest{
+		prefix: strings.TrimSuffix(prefix, "/") + "/",
+		byName: make(map[string]string),
+		byHash: make(map[string]string),
+		etags:  make(map[string]string),
+	}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name != "." && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(b)
+		hash := hex.EncodeToString(sum[:])[:hashLen]
+		fp := fingerprint(name, hash)
+		m.byName[name] = fp
+		m.byHash[fp] = name
+		m.etags[name] = `"` + hash + `"`
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("assets: %w", err)
+	}
+	return m, nil
+}
+
+// fingerprint puts hash before the extension: "css/a.css" -> "css/a.<hash>.css".
+func fingerprint(name, hash string) string {
+	ext := path.Ext(name)
+	if ext == path.Base(name) {
+		ext = "" // ".env"-style names have no extension to keep
+	}
+	return strings.TrimSuffix(name, ext) + "." + hash + ext
+}
+
+// URL returns the fingerprinted URL of a static file, e.g. "/static/a.css"
+// -> "/static/a.3f2a9c1e7b.css". URLs outside the prefix, with a query or
+// fragment, or naming no file are returned unchanged.
+func (m *Manifest) URL(u string) string {
+	if m == nil || strings.ContainsAny(u, "?#") {
+		return u
+	}
+	name, ok := strings.CutPrefix(u, m.prefix)
+	if !ok {
+		return u
+	}
+	if fp, ok := m.byName[name]; ok {
+		return m.prefix + fp
+	}
+	return u
+}
+
+// Files returns every plain file name (relative to the prefix) with its
+// fingerprinted name, for exporting both.
+func (m *Manifest) Files() map[string]string {
+	out := make(map[string]string)
+	if m != nil {
+		for name, fp := range m.byName {
+			out[name] = fp
+		}
+	}
+	return out
+}
+
+// Handler serves fingerprinted URLs from next as their plain name with
+// ImmutableCache; everything else passes through with RevalidateCache.
+// next sees the full, plain URL path.
+func (m *Manifest) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m != nil {
+			name := strings.TrimPrefix(r.URL.Path, m.prefix)
+			if plain, ok := m.byHash[name]; ok {
+				r2 := r.Clone(r.Context())
+				r2.URL.Path = m.prefix + plain
+				r2.URL.RawPath = ""
+				w.Header().Set("Cache-Control", ImmutableCache)
+				w.Header().Set("ETag", m.etags[plain])
+				next.ServeHTTP(w, r2)
+				return
+			}
+			if etag, ok := m.etags[name]; ok {
+				w.Header().Set("ETag", etag)
+			}
+		}
+		w.Header().Set("Cache-Control", RevalidateCache)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,101 @@
+package assets
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"testing/fstest"
+)
+
+func TestURL_FingerprintsKnownFiles(t *testing.T) {
+	m, err := New(fstest.MapFS{
+		"css/01-base.css": {Data: []byte("body{}")},
+		"js/main.js":      {Data: []byte("export {}")},
+		"LICENSE":         {Data: []byte("MIT")},
+		".hidden/x.css":   {Data: []byte("x")},
+	}, "/static")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	css := m.URL("/static/css/01-base.css")
+	if !regexp.MustCompile(`^/static/css/01-base\.[0-9a-f]{10}\.css$`).MatchString(css) {
+		t.Fatalf("URL = %q", css)
+	}
+	if got := m.URL("/static/LICENSE"); !regexp.MustCompile(`^/static/LICENSE\.[0-9a-f]{10}$`).MatchString(got) {
+		t.Fatalf("URL(LICENSE) = %q", got)
+	}
+	for _, u := range []string{
+		"/static/css/missing.css",
+		"/static/css/01-base.css?v=2",
+		"/static/.hidden/x.css",
+		"https://cdn.example.com/static/css/01-base.css",
+		"/blog/feed.atom",
+	} {
+		if got := m.URL(u); got != u {
+			t.Fatalf("URL(%q) = %q, want it unchanged", u, got)
+		}
+	}
+	if files := m.Files(); len(files) != 3 || "/static/"+files["css/01-base.css"] != css {
+		t.Fatalf("Files = %v", files)
+	}
+
+	// Same content, same fingerprint; new content, new one.
+	again, _ := New(fstest.MapFS{"css/01-base.css": {Data: []byte("body{}")}}, "/static/")
+	changed, _ := New(fstest.MapFS{"css/01-base.css": {Data: []byte("body{margin:0}")}}, "/static/")
+	if again.URL("/static/css/01-base.css") != css || changed.URL("/static/css/01-base.css") == css {
+		t.Fatal("fingerprint does not follow content")
+	}
+
+	var none *Manifest
+	if got := none.URL("/static/css/01-base.css"); got != "/static/css/01-base.css" {
+		t.Fatalf("nil URL = %q", got)
+	}
+}
+
+func TestHandler_CachesByURL(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "css"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "css", "site.css"), []byte("body{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := New(os.DirFS(dir), "/static/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := m.Handler(http.StripPrefix("/static/", http.FileServer(http.Dir(dir))))
+	serve := func(path, etag string) *http.Response {
+		req := httptest.NewRequest("GET", path, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr.Result()
+	}
+
+	fp := m.URL("/static/css/site.css")
+	resp := serve(fp, "")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Cache-Control") != ImmutableCache {
+		t.Fatalf("%s: status %d, Cache-Control %q", fp, resp.StatusCode, resp.Header.Get("Cache-Control"))
+	}
+	etag := resp.Header.Get("ETag")
+
+	resp = serve("/static/css/site.css", "")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Cache-Control") != RevalidateCache || resp.Header.Get("ETag") != etag {
+		t.Fatalf("plain: status %d, headers %v", resp.StatusCode, resp.Header)
+	}
+	if resp = serve("/static/css/site.css", etag); resp.StatusCode != http.StatusNotModified {
+		t.Fatalf("revalidation: status %d, want 304", resp.StatusCode)
+	}
+
+	// A stale fingerprint is not quietly served as the current file.
+	if resp = serve("/static/css/site.0123456789.css", ""); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("stale fingerprint: status %d, want 404", resp.StatusCode)
+	}
+}
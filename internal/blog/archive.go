@@ -0,0 +1,77 @@
+// internal/blog/archive.go
+package blog
+
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
+/*
+Date archive: posts grouped by year and month, newest first. Posts without
+a date are counted separately rather than dropped.
+*/
+
+// Archive summarizes every post by publication date.
+type Archive struct {
+	Years   []ArchiveYear // newest first
+	Undated int           // posts with a zero Date
+}
+
+// ArchiveYear is one year of posts.
+type ArchiveYear struct {
+	Year   int
+	Count  int
+	Months []ArchiveMonth // newest first; only months with posts
+}
+
+// ArchiveMonth is one month of posts.
+type ArchiveMonth struct {
+	Year  int
+	Month time.Month
+	Count int
+}
+
+// buildArchive groups posts by the year and month of their Date, the same
+// way inPeriod filters them. Grouping by key rather than in sort order keeps
+// posts in other time zones near a month boundary from splitting a month.
+func buildArchive(posts []Post) Archive {
+	var a Archive
+	counts := make(map[int]map[time.Month]int)
+	for _, p := range posts {
+		if p.Date.IsZero() {
+			a.Undated++
+			continue
+		}
+		y, m := p.Date.Year(), p.Date.Month()
+		if counts[y] == nil {
+			counts[y] = make(map[time.Month]int)
+		}
+		counts[y][m]++
+	}
+	years := slices.Sorted(maps.Keys(counts))
+	slices.Reverse(years)
+	for _, y := range years {
+		ay := ArchiveYear{Year: y}
+		months := slices.Sorted(maps.Keys(counts[y]))
+		slices.Reverse(months)
+		for _, m := range months {
+			ay.Count += counts[y][m]
+			ay.Months = append(ay.Months, ArchiveMonth{Year: y, Month: m, Count: counts[y][m]})
+		}
+		a.Years = append(a.Years, ay)
+	}
+	return a
+}
+
+// inPeriod reports whether p belongs to year/month; month 0 means the whole
+// year, and year 0 selects undated posts.
+func inPeriod(p Post, year int, month time.Month) bool {
+	if year == 0 {
+		return p.Date.IsZero()
+	}
+	if p.Date.IsZero() || p.Date.Year() != year {
+		return false
+	}
+	return month == 0 || p.Date.Month() == month
+}
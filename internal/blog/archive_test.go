@@ -0,0 +1,76 @@
+package blog
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFilesStore_Archive(t *testing.T) {
+	td := t.TempDir()
+	write(t, td, "a.md", "---\ntitle: A\ndate: 2025-08-20\n---\na")
+	write(t, td, "b.md", "---\ntitle: B\ndate: 2025-08-01\n---\nb")
+	write(t, td, "c.md", "---\ntitle: C\ndate: 2025-03-10\n---\nc")
+	write(t, td, "d.md", "---\ntitle: D\ndate: 2024-12-31\n---\nd")
+	write(t, td, "e.md", "---\ntitle: E\n---\nundated")
+
+	s, err := NewFilesStore(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Archive{
+		Years: []ArchiveYear{
+			{Year: 2025, Count: 3, Months: []ArchiveMonth{{2025, time.August, 2}, {2025, time.March, 1}}},
+			{Year: 2024, Count: 1, Months: []ArchiveMonth{{2024, time.December, 1}}},
+		},
+		Undated: 1,
+	}
+	if got := s.Archive(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("archive = %+v\nwant %+v", got, want)
+	}
+
+	slugs := func(ps []Post) (out []string) {
+		for _, p := range ps {
+			out = append(out, p.Slug)
+		}
+		return out
+	}
+	for _, tc := range []struct {
+		year  int
+		month time.Month
+		want  []string
+	}{
+		{2025, 0, []string{"a", "b", "c"}},
+		{2025, time.August, []string{"a", "b"}},
+		{2024, time.January, nil},
+		{0, 0, []string{"e"}},
+	} {
+		if got := slugs(s.ByDate(tc.year, tc.month)); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ByDate(%d, %d) = %v, want %v", tc.year, tc.month, got, tc.want)
+		}
+	}
+}
+
+// Posts are sorted by instant but archived by their own calendar date, so
+// offsets around a year boundary can interleave the two.
+func TestFilesStore_ArchiveMixedTimeZones(t *testing.T) {
+	td := t.TempDir()
+	write(t, td, "a.md", "---\ntitle: A\ndate: 2025-01-01T00:30:00Z\n---\na")
+	write(t, td, "b.md", "---\ntitle: B\ndate: 2024-12-31T23:30:00Z\n---\nb")
+	write(t, td, "c.md", "---\ntitle: C\ndate: 2025-01-01T01:00:00+02:00\n---\nc")
+
+	s, err := NewFilesStore(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Archive{Years: []ArchiveYear{
+		{Year: 2025, Count: 2, Months: []ArchiveMonth{{2025, time.January, 2}}},
+		{Year: 2024, Count: 1, Months: []ArchiveMonth{{2024, time.December, 1}}},
+	}}
+	if got := s.Archive(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("archive = %+v\nwant %+v", got, want)
+	}
+	if got := len(s.ByDate(2025, time.January)); got != 2 {
+		t.Fatalf("ByDate(2025, January) = %d posts, want 2", got)
+	}
+}
@@ -0,0 +1,187 @@
+// internal/blog/bundle.go
+package blog
+
+import (
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+/*
+Content layout: nested directories and page bundles.
+
+Posts may live anywhere under the content directory:
+
+	hello.md              plain post
+	2025/launch.md        plain post, directories are just for filing
+	trip/index.md         page bundle: slug defaults to "trip"
+	trip/map.png          asset, served as /blog/trip/map.png
+
+A bundle is any directory (other than the root) with an index.md. Its other
+files are assets, not posts; relative image links in index.md (and links to
+files that exist in the bundle) are rewritten to /blog/{slug}/... when the
+post renders. Raw HTML is left alone. Directories and files starting with
+"." are ignored.
+*/
+
+// contentFile is a post source found by listContent.
+type contentFile struct {
+	path   string // on disk
+	rel    string // slash-separated, relative to the content dir
+	bundle string // bundle directory on disk; "" for plain posts
+	// ignored lists other Markdown files in a bundle, relative to the
+	// content dir: they are neither posts nor servable assets.
+	ignored []string
+}
+
+// listContent walks dir in lexical order and returns every post source.
+func listContent(dir string) ([]contentFile, error) {
+	var out []contentFile
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == dir {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			index := filepath.Join(p, "index.md")
+			if info, err := os.Stat(index); err == nil && !info.IsDir() {
+				ignored, err := bundleMarkdown(p, filepath.ToSlash(rel))
+				if err != nil {
+					return err
+				}
+				out = append(out, contentFile{path: index, rel: filepath.ToSlash(filepath.Join(rel, "index.md")), bundle: p, ignored: ignored})
+				return filepath.SkipDir // everything else in a bundle is an asset
+			}
+			return nil
+		}
+		if strings.HasSuffix(d.Name(), ".md") {
+			out = append(out, contentFile{path: p, rel: filepath.ToSlash(rel)})
+		}
+		return nil
+	})
+	return out, err
+}
+
+// bundleMarkdown lists the Markdown files of a bundle other than its
+// index.md, as rel/name, including those in sub-directories.
+func bundleMarkdown(dir, rel string) ([]string, error) {
+	var out []string
+	err := fs.WalkDir(os.DirFS(dir), ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name != "." && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && name != "index.md" && strings.EqualFold(path.Ext(name), ".md") {
+			out = append(out, path.Join(rel, name))
+		}
+		return nil
+	})
+	return out, err
+}
+
+// assetFS exposes a bundle directory without its Markdown or hidden files.
+type assetFS struct{ fsys fs.FS }
+
+func (a assetFS) Open(name string) (fs.File, error) {
+	if !isAsset(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return a.fsys.Open(name)
+}
+
+func (a assetFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !isAsset(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	entries, err := fs.ReadDir(a.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	out := entries[:0]
+	for _, e := range entries {
+		if isAsset(path.Join(name, e.Name())) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// isAsset reports whether a bundle-relative name may be served.
+func isAsset(name string) bool {
+	if name == "." {
+		return true
+	}
+	if !fs.ValidPath(name) {
+		return false
+	}
+	for _, elem := range strings.Split(name, "/") {
+		if strings.HasPrefix(elem, ".") {
+			return false
+		}
+	}
+	return !strings.EqualFold(path.Ext(name), ".md")
+}
+
+// rewriteBundleLinks points relative image destinations, and link
+// destinations naming a file in the bundle, at base ("/blog/{slug}/").
+func rewriteBundleLinks(doc ast.Node, base string, assets fs.FS) {
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch t := n.(type) {
+		case *ast.Image:
+			if ref, _, ok := bundleRef(string(t.Destination)); ok {
+				t.Destination = []byte(base + ref)
+			}
+		case *ast.Link:
+			if ref, file, ok := bundleRef(string(t.Destination)); ok {
+				if _, err := fs.Stat(assets, file); err == nil {
+					t.Destination = []byte(base + ref)
+				}
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// bundleRef cleans a relative URL ("./img/a.png#x" -> "img/a.png#x") and
+// returns it with the file name it refers to. Absolute URLs, fragments and
+// paths escaping the bundle are not bundle references.
+func bundleRef(dest string) (ref, file string, ok bool) {
+	if dest == "" || strings.HasPrefix(dest, "/") || strings.HasPrefix(dest, "#") {
+		return "", "", false
+	}
+	u, err := url.Parse(dest)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+		return "", "", false
+	}
+	file = path.Clean(u.Path)
+	if file == "." || file == ".." || strings.HasPrefix(file, "../") {
+		return "", "", false
+	}
+	u.Path = file
+	return u.String(), file, true
+}
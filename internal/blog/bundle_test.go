@@ -0,0 +1,180 @@
+package blog
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFilesStore_NestedAndBundles(t *testing.T) {
+	td := t.TempDir()
+	for _, d := range []string{"2025", "trip/img", ".git"} {
+		if err := os.MkdirAll(filepath.Join(td, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(t, td, "top.md", "---\ntitle: Top\n---\ntop")
+	write(t, td, "2025/nested.md", "---\ntitle: Nested Post\n---\nnested")
+	write(t, td, ".git/ignored.md", "---\ntitle: Ignored\n---\nx")
+	write(t, td, "trip/index.md", "---\ntitle: Our Summer Trip\n---\n"+
+		"![map](map.png) ![pic](./img/a.png \"A\") ![ext](https://example.com/x.png) ![up](../top.png)\n\n"+
+		"[notes](notes.txt) [missing](nope.txt) [site](/blog) [frag](#top)\n")
+	write(t, td, "trip/map.png", "png")
+	write(t, td, "trip/img/a.png", "png")
+	write(t, td, "trip/notes.txt", "notes")
+	write(t, td, "trip/draft-part.md", "---\ntitle: Not a post\n---\nx")
+
+	s, err := NewFilesStore(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var slugs []string
+	for _, p := range s.All() {
+		slugs = append(slugs, p.Slug)
+	}
+	if got := strings.Join(slugs, ","); got != "nested-post,trip,top" {
+		t.Fatalf("slugs = %s, want nested-post,trip,top", got)
+	}
+	if p, _ := s.BySlug("nested-post"); p.Source != "2025/nested.md" {
+		t.Fatalf("nested source = %q", p.Source)
+	}
+
+	trip, ok := s.BySlug("trip")
+	if !ok || trip.Title != "Our Summer Trip" || trip.Source != "trip/index.md" {
+		t.Fatalf("bundle = %+v, %v", trip, ok)
+	}
+	html := string(trip.HTML)
+	for _, want := range []string{
+		`src="/blog/trip/map.png"`,
+		`src="/blog/trip/img/a.png"`,
+		`src="https://example.com/x.png"`,
+		`src="../top.png"`,
+		`href="/blog/trip/notes.txt"`,
+		`href="nope.txt"`,
+		`href="/blog"`,
+		`href="#top"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("bundle HTML missing %s:\n%s", want, html)
+		}
+	}
+
+	fsys, ok := s.BundleFS("trip")
+	if !ok {
+		t.Fatal("no bundle fs for trip")
+	}
+	var files []string
+	_ = fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files = append(files, p)
+		}
+		return err
+	})
+	if got := strings.Join(files, ","); got != "img/a.png,map.png,notes.txt" {
+		t.Fatalf("bundle assets = %s", got)
+	}
+	if _, err := fs.Stat(fsys, "index.md"); err == nil {
+		t.Fatal("bundle fs exposes index.md")
+	}
+	if _, ok := s.BundleFS("top"); ok {
+		t.Fatal("plain post has a bundle fs")
+	}
+}
+
+func TestFilesStore_WarnsAboutIgnoredBundleMarkdown(t *testing.T) {
+	td := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(td, "trip/2025"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write(t, td, "trip/index.md", "---\ntitle: Trip\n---\nx")
+	write(t, td, "trip/day1.md", "---\ntitle: Day 1\n---\nx")
+	write(t, td, "trip/2025/later.md", "---\ntitle: Later\n---\nx")
+	write(t, td, "trip/notes.txt", "notes")
+
+	var logs bytes.Buffer
+	s, err := NewFilesStore(td, WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(s.All()); got != 1 {
+		t.Fatalf("posts = %d, want only the bundle", got)
+	}
+	out := logs.String()
+	if n := strings.Count(out, "blog_bundle_markdown_ignored"); n != 2 {
+		t.Fatalf("%d ignored-markdown warnings, want 2:\n%s", n, out)
+	}
+	for _, want := range []string{"file=trip/day1.md", "file=trip/2025/later.md"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("no warning for %s:\n%s", want, out)
+		}
+	}
+}
+
+func TestFilesStore_BundleSlugFollowsResolution(t *testing.T) {
+	td := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(td, "trip"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write(t, td, "a.md", "---\ntitle: Trip\n---\nclaims the slug first")
+	write(t, td, "trip/index.md", "---\ntitle: Trip Bundle\n---\n![map](map.png)")
+	write(t, td, "trip/map.png", "png")
+
+	s, err := NewFilesStore(td, WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, ok := s.BySlug("trip-2")
+	if !ok || !strings.Contains(string(p.HTML), `src="/blog/trip-2/map.png"`) {
+		t.Fatalf("bundle links should follow the resolved slug: %+v", p)
+	}
+	if _, ok := s.BundleFS("trip-2"); !ok {
+		t.Fatal("assets not served under the resolved slug")
+	}
+}
+
+// recordImages stands in for internal/images, noting what each post passed.
+type recordImages map[string]bool // base -> had assets
+
+func (r recordImages) RewriteHTML(html []byte, base string, assets fs.FS) ([]byte, error) {
+	r[base] = assets != nil
+	return []byte(strings.ReplaceAll(string(html), "<img ", `<img width="1" `)), nil
+}
+
+func TestFilesStore_WithImages(t *testing.T) {
+	td := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(td, "trip"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write(t, td, "trip/index.md", "---\ntitle: Trip\n---\n![map](map.png)\n")
+	write(t, td, "trip/map.png", "png")
+	write(t, td, "plain.md", "---\ntitle: Plain\n---\n![logo](/static/logo.png)\n")
+
+	rec := recordImages{}
+	s, err := NewFilesStore(td, WithImages(rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rec["/blog/trip/"] || rec["/blog/plain/"] || len(rec) != 2 {
+		t.Fatalf("rewriter calls = %v, want trip with assets and plain without", rec)
+	}
+	p, _ := s.BySlug("trip")
+	if !strings.Contains(string(p.HTML), `<img width="1" src="/blog/trip/map.png"`) {
+		t.Fatalf("rewritten HTML not kept: %s", p.HTML)
+	}
+
+	// The HTML depends on the image, so replacing it must trigger a reload.
+	before, err := s.fingerprint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	write(t, td, "trip/map.png", "a bigger png")
+	if after, _ := s.fingerprint(); after == before {
+		t.Fatal("fingerprint unchanged after a bundle asset was replaced")
+	}
+}
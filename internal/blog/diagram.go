@@ -0,0 +1,103 @@
+// internal/blog/diagram.go
+package blog
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+/*
+Diagram blocks.
+
+Fenced blocks in a diagram language have no pure-Go layout engine to turn
+them into SVG, so they are passed through as a labelled figure holding the
+escaped source:
+
+	<figure class="diagram" data-diagram="mermaid">
+	<pre class="diagram__source"><code>graph LR; a --> b</code></pre>
+	<figcaption class="diagram__caption">Mermaid diagram (source)</figcaption>
+	</figure>
+
+The source reads fine as text, nothing is loaded to render it, and
+data-diagram marks the block for a client-side renderer should the site
+ever opt into one.
+*/
+
+// Diagrams is a goldmark extension that passes diagram code blocks through
+// as marked-up figures instead of highlighting them as code.
+var Diagrams goldmark.Extender = diagrams{}
+
+// diagramLangs maps fence languages to the name shown in the caption.
+var diagramLangs = map[string]string{
+	"mermaid":  "Mermaid",
+	"dot":      "Graphviz",
+	"graphviz": "Graphviz",
+}
+
+type diagrams struct{}
+
+func (diagrams) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(util.Prioritized(diagramTransformer{}, 100)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(diagramRenderer{}, 100)))
+}
+
+var kindDiagram = ast.NewNodeKind("Diagram")
+
+// diagramBlock is a diagram's source; the text is in Lines.
+type diagramBlock struct {
+	ast.BaseBlock
+	lang string // key of diagramLangs
+}
+
+func (n *diagramBlock) Kind() ast.NodeKind { return kindDiagram }
+func (n *diagramBlock) IsRaw() bool        { return true }
+
+func (n *diagramBlock) Dump(src []byte, level int) {
+	ast.DumpHelper(n, src, level, map[string]string{"Lang": n.lang}, nil)
+}
+
+type diagramTransformer struct{}
+
+func (diagramTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	src := reader.Source()
+	replaceFences(doc, func(fb *ast.FencedCodeBlock) ast.Node {
+		lang := strings.ToLower(string(fb.Language(src)))
+		if _, ok := diagramLangs[lang]; !ok {
+			return nil
+		}
+		n := &diagramBlock{lang: lang}
+		n.SetLines(fb.Lines())
+		return n
+	})
+}
+
+type diagramRenderer struct{}
+
+func (diagramRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindDiagram, renderDiagram)
+}
+
+func renderDiagram(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*diagramBlock)
+	_, _ = w.WriteString(`<figure class="diagram" data-diagram="`)
+	_, _ = w.WriteString(n.lang)
+	_, _ = w.WriteString(`">` + "\n" + `<pre class="diagram__source"><code>`)
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		seg := lines.At(i)
+		_, _ = w.Write(util.EscapeHTML(seg.Value(src)))
+	}
+	_, _ = w.WriteString("</code></pre>\n" + `<figcaption class="diagram__caption">`)
+	_, _ = w.WriteString(diagramLangs[n.lang])
+	_, _ = w.WriteString(" diagram (source)</figcaption>\n</figure>\n")
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,33 @@
+package blog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiagrams_PassThrough(t *testing.T) {
+	src := "```mermaid\ngraph LR\n  a --> b\n```\n\n```dot\ndigraph { a -> b }\n```\n\n```go\nx := 1\n```\n"
+	html := render(t, MarkdownOptions{}, src)
+	for _, want := range []string{
+		`<figure class="diagram" data-diagram="mermaid">`,
+		"<pre class=\"diagram__source\"><code>graph LR\n  a --&gt; b\n</code></pre>",
+		`<figcaption class="diagram__caption">Mermaid diagram (source)</figcaption>`,
+		`<figure class="diagram" data-diagram="dot">`,
+		`Graphviz diagram (source)`,
+		`class="chroma"`, // other fences are still highlighted
+	} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("missing %q in:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "<script") {
+		t.Fatalf("diagram pulled in a script:\n%s", html)
+	}
+}
+
+func TestDiagrams_Disabled(t *testing.T) {
+	html := render(t, MarkdownOptions{Extensions: []string{"highlight"}}, "```mermaid\ngraph LR\n```\n")
+	if strings.Contains(html, "diagram") {
+		t.Fatalf("diagram rendered without the extension: %s", html)
+	}
+}
@@ -0,0 +1,108 @@
+// internal/blog/excerpt.go
+package blog
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+/*
+Derived post metadata: word count, reading time and excerpts.
+*/
+
+// wordsPerMinute is the reading speed used for Post.ReadingTime.
+const wordsPerMinute = 220
+
+// excerptRunes caps an auto-generated excerpt.
+const excerptRunes = 280
+
+// moreMarker splits a post's excerpt from the rest of its body.
+var moreMarker = []byte("<!--more-->")
+
+// readingTime returns whole minutes to read words, at least 1 for any text.
+func readingTime(words int) int {
+	if words == 0 {
+		return 0
+	}
+	return max(1, (words+wordsPerMinute/2)/wordsPerMinute)
+}
+
+// countWords counts words in prose and code across the document. Inline
+// text is joined before splitting because the parser may break a single
+// word over several nodes ("three" + ".").
+func countWords(doc ast.Node, src []byte) int {
+	var b bytes.Buffer
+	_ = ast.Walk(doc, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if c.Type() == ast.TypeBlock {
+			b.WriteByte(' ')
+		}
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch t := c.(type) {
+		case *ast.Text:
+			b.Write(t.Segment.Value(src))
+			if t.SoftLineBreak() || t.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(t.Value)
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			lines := c.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				seg := lines.At(i)
+				b.Write(seg.Value(src))
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return len(strings.Fields(stripPlaceholders(b.String())))
+}
+
+// excerpt derives a plain-text summary: everything before a <!--more-->
+// marker if present, otherwise the first paragraph. The result is trimmed
+// to about excerptRunes at a word boundary.
+func excerpt(p parser.Parser, doc ast.Node, body []byte) string {
+	src := body
+	if i := bytes.Index(body, moreMarker); i >= 0 {
+		src = body[:i]
+		doc = p.Parse(text.NewReader(src))
+	}
+
+	var parts []string
+	for c := doc.FirstChild(); c != nil; c = c.NextSibling() {
+		if c.Kind() != ast.KindParagraph {
+			continue
+		}
+		t := strings.TrimSpace(stripPlaceholders(nodeText(c, src)))
+		if t == "" {
+			continue // a shortcode on its own
+		}
+		parts = append(parts, t)
+		if len(src) == len(body) {
+			break // no marker: first paragraph only
+		}
+	}
+	return truncateWords(strings.Join(parts, " "), excerptRunes)
+}
+
+// truncateWords shortens s to at most n runes, cutting at a space and
+// adding an ellipsis when anything was dropped.
+func truncateWords(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	r := []rune(s)[:n]
+	cut := string(r)
+	if i := strings.LastIndexByte(cut, ' '); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, ",;:.-–— ") + "…"
+}
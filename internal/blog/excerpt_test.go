@@ -0,0 +1,61 @@
+package blog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilesStore_WordCountAndReadingTime(t *testing.T) {
+	td := t.TempDir()
+	write(t, td, "short.md", "---\ntitle: Short\n---\nOne *two* three.\n\n```go\nfour five\n```\n")
+	write(t, td, "long.md", "---\ntitle: Long\n---\n"+strings.Repeat("word ", 1000)+"\n")
+
+	s, err := NewFilesStore(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	short, _ := s.BySlug("short")
+	if short.WordCount != 5 || short.ReadingTime != 1 {
+		t.Fatalf("short: words=%d minutes=%d, want 5 and 1", short.WordCount, short.ReadingTime)
+	}
+	long, _ := s.BySlug("long")
+	if long.WordCount != 1000 || long.ReadingTime != 5 {
+		t.Fatalf("long: words=%d minutes=%d, want 1000 and 5", long.WordCount, long.ReadingTime)
+	}
+}
+
+func TestFilesStore_Excerpt(t *testing.T) {
+	td := t.TempDir()
+	write(t, td, "first.md", "---\ntitle: First\n---\n# Heading\n\nThe *first* paragraph\nwraps.\n\nSecond paragraph.\n")
+	write(t, td, "more.md", "---\ntitle: More\n---\nIntro one.\n\nIntro two.\n\n<!--more-->\n\nRest of the post.\n")
+	write(t, td, "given.md", "---\ntitle: Given\nsummary: From front matter.\n---\nBody text.\n")
+	write(t, td, "long.md", "---\ntitle: Long\n---\n"+strings.Repeat("lorem ipsum ", 100)+"\n")
+
+	s, err := NewFilesStore(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for slug, want := range map[string]string{
+		"first": "The first paragraph wraps.",
+		"more":  "Intro one. Intro two.",
+		"given": "From front matter.",
+	} {
+		p, _ := s.BySlug(slug)
+		if p.Summary != want {
+			t.Errorf("%s: summary=%q, want %q", slug, p.Summary, want)
+		}
+	}
+
+	more, _ := s.BySlug("more")
+	if !strings.Contains(string(more.HTML), "Rest of the post") {
+		t.Fatalf("body lost after marker:\n%s", more.HTML)
+	}
+	if strings.Contains(string(more.HTML), "<!--") || strings.Contains(string(more.HTML), "omitted") {
+		t.Fatalf("marker leaked into HTML:\n%s", more.HTML)
+	}
+
+	long, _ := s.BySlug("long")
+	if n := len([]rune(long.Summary)); n > excerptRunes+1 || !strings.HasSuffix(long.Summary, "…") {
+		t.Fatalf("long summary not truncated (%d runes): %q", n, long.Summary)
+	}
+}
@@ -3,34 +3,74 @@ package blog
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"html/template"
+	"io/fs"
+	"log/slog"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/yuin/goldmark"
-	"gopkg.in/yaml.v3"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
 )
 
 /*
 Filesystem-backed implementation of the blog Store.
 
-- Reads Markdown files with YAML front matter from a directory.
-- Filters drafts/future-dated posts unless configured to show drafts.
-- Renders Markdown to HTML using goldmark.
+- Reads Markdown files with YAML front matter from a directory tree,
+  including page bundles (see bundle.go).
+- Hides drafts/future-dated posts unless configured to show drafts; they
+  stay loaded for Preview, and scheduled posts go live at their date.
+- Renders Markdown to HTML using goldmark, resolving wiki links between
+  posts (see wikilink.go).
 - Ensures unique slugs and provides fast slug lookup.
+- Optionally watches the directory and swaps in a fresh index on change.
 */
 
 type FilesStore struct {
 	dir        string
 	showDrafts bool
 	now        func() time.Time
+	log        *slog.Logger
+	md         goldmark.Markdown
+	tocMin     int
+	tocMax     int
+	authors    map[string]Author
+	shortcodes *template.Template // nil: no shortcodes defined
+	site       any                // .Site in shortcode templates
+	images     ImageRewriter      // nil: images are left as written
+
+	mu  sync.RWMutex
+	idx *index
+}
 
-	posts  []Post
-	bySlug map[string]int
+// index is an immutable snapshot of the loaded posts; reload swaps it whole.
+type index struct {
+	posts   []Post
+	bySlug  map[string]int
+	tags    []Tag            // most used first
+	byTag   map[string][]int // tag slug -> post indexes, date desc
+	series  map[string][]int // series slug -> post indexes, reading order
+	search  *searchIndex
+	related [][]int           // post index -> related post indexes, best first
+	aliases map[string]string // old slug -> canonical slug
+	bundles map[string]fs.FS  // slug -> assets of a page bundle, published or not
+	hidden  map[string]Post   // drafts and scheduled posts, by slug; previews only
+	all     []Post            // every loaded post in load order, for re-indexing
+	next    time.Time         // when the next scheduled post goes live; zero if none
+	archive Archive
+	graph   Graph
+	sig     string // directory fingerprint the snapshot was loaded from
 }
 
 // Functional options
@@ -42,73 +82,349 @@ func WithDrafts(v bool) FilesOption { return func(s *FilesStore) { s.showDrafts
 // WithNow overrides the time source (useful for tests).
 func WithNow(f func() time.Time) FilesOption { return func(s *FilesStore) { s.now = f } }
 
+// WithMarkdown sets the Markdown pipeline (see NewMarkdown).
+func WithMarkdown(m goldmark.Markdown) FilesOption { return func(s *FilesStore) { s.md = m } }
+
+// WithTOCDepth limits the table of contents to heading levels min..max.
+// Out-of-range values fall back to DefaultTOCMin/DefaultTOCMax.
+func WithTOCDepth(min, max int) FilesOption {
+	return func(s *FilesStore) { s.tocMin, s.tocMax = min, max }
+}
+
+// WithAuthors sets the authors posts may name in `authors:`, keyed by ID.
+func WithAuthors(authors []Author) FilesOption {
+	return func(s *FilesStore) {
+		s.authors = make(map[string]Author, len(authors))
+		for _, a := range authors {
+			s.authors[a.ID] = a
+		}
+	}
+}
+
+// WithShortcodes enables {{< name >}} shortcodes in posts, each rendered
+// by the template "shortcodes/<name>" in t. site is passed to every call
+// as .Site.
+func WithShortcodes(t *template.Template, site any) FilesOption {
+	return func(s *FilesStore) { s.shortcodes, s.site = t, site }
+}
+
+// ImageRewriter adds responsive variants to the images of a rendered post
+// (see internal/images). base is the post's URL path ("/blog/{slug}/") and
+// assets its page bundle, nil for plain posts.
+type ImageRewriter interface {
+	RewriteHTML(html []byte, base string, assets fs.FS) ([]byte, error)
+}
+
+// WithImages rewrites the <img> tags of every rendered post through r.
+func WithImages(r ImageRewriter) FilesOption { return func(s *FilesStore) { s.images = r } }
+
+// WithLogger sets the logger used to report reload failures while watching.
+func WithLogger(l *slog.Logger) FilesOption { return func(s *FilesStore) { s.log = l } }
+
 // NewFilesStore loads posts from dir and prepares indexes.
 func NewFilesStore(dir string, opts ...FilesOption) (*FilesStore, error) {
 	s := &FilesStore{
 		dir: dir,
 		now: time.Now,
+		log: slog.Default(),
 	}
 	for _, opt := range opts {
 		opt(s)
 	}
+	if s.md == nil {
+		s.md = defaultMarkdown()
+	}
+	if s.tocMin < 1 || s.tocMin > 6 {
+		s.tocMin = DefaultTOCMin
+	}
+	if s.tocMax < s.tocMin || s.tocMax > 6 {
+		s.tocMax = max(DefaultTOCMax, s.tocMin)
+	}
 	if err := s.reload(); err != nil {
 		return nil, err
 	}
 	return s, nil
 }
 
+// snapshot returns the current index. Callers must treat it as read-only.
+// Once a scheduled post comes due, the first caller re-indexes so it goes
+// live on time without a restart or a file change.
+func (s *FilesStore) snapshot() *index {
+	s.mu.RLock()
+	idx := s.idx
+	s.mu.RUnlock()
+	if idx.next.IsZero() || s.now().Before(idx.next) {
+		return idx
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.idx != idx {
+		return s.idx // another caller (or a reload) got there first
+	}
+	s.idx = s.buildIndex(idx.all, idx.bundles, idx.sig, s.now())
+	s.log.Info("blog_publish", slog.Int("posts", len(s.idx.posts)))
+	return s.idx
+}
+
 // All returns all posts sorted by date desc (copy).
 func (s *FilesStore) All() []Post {
-	out := make([]Post, len(s.posts))
-	copy(out, s.posts)
+	idx := s.snapshot()
+	out := make([]Post, len(idx.posts))
+	copy(out, idx.posts)
 	return out
 }
 
+// Page returns the 1-based page of posts (date desc) of the given size and
+// the total number of posts. Out-of-range pages yield no posts.
+func (s *FilesStore) Page(page, size int) ([]Post, int) {
+	idx := s.snapshot()
+	total := len(idx.posts)
+	if page < 1 || size < 1 {
+		return nil, total
+	}
+	start := (page - 1) * size
+	if start >= total {
+		return nil, total
+	}
+	end := min(start+size, total)
+	out := make([]Post, end-start)
+	copy(out, idx.posts[start:end])
+	return out, total
+}
+
+// Alias returns the canonical slug for an old slug listed in a post's
+// `aliases:` front matter.
+func (s *FilesStore) Alias(slug string) (string, bool) {
+	canonical, ok := s.snapshot().aliases[slug]
+	return canonical, ok
+}
+
+// BundleFS returns the assets of a page-bundle post: every file next to its
+// index.md except Markdown and hidden files. Unpublished posts have bundles
+// too, so callers must gate those the same way as Preview.
+func (s *FilesStore) BundleFS(slug string) (fs.FS, bool) {
+	fsys, ok := s.snapshot().bundles[slug]
+	return fsys, ok
+}
+
+// Preview returns an unpublished (draft or future-dated) post by slug.
+// Published posts are not returned; use BySlug.
+func (s *FilesStore) Preview(slug string) (Post, bool) {
+	p, ok := s.snapshot().hidden[slug]
+	return p, ok
+}
+
 // BySlug returns a post by its slug.
 func (s *FilesStore) BySlug(slug string) (Post, bool) {
-	i, ok := s.bySlug[slug]
+	idx := s.snapshot()
+	i, ok := idx.bySlug[slug]
 	if !ok {
 		return Post{}, false
 	}
-	return s.posts[i], true
+	return idx.posts[i], true
 }
 
-// ByTag returns posts with a given tag (case-insensitive), sorted by date desc.
+// ByTag returns posts with a given tag, sorted by date desc. The tag may be
+// given in display form ("Go Lang") or URL form ("go-lang"); see TagSlug.
 func (s *FilesStore) ByTag(tag string) []Post {
-	tag = strings.ToLower(tag)
+	idx := s.snapshot()
+	ids := idx.byTag[TagSlug(tag)]
+	if len(ids) == 0 {
+		return nil
+	}
+	out := make([]Post, len(ids))
+	for i, id := range ids {
+		out[i] = idx.posts[id]
+	}
+	return out
+}
+
+// Tags returns every tag with its post count, most used first (copy).
+func (s *FilesStore) Tags() []Tag {
+	idx := s.snapshot()
+	out := make([]Tag, len(idx.tags))
+	copy(out, idx.tags)
+	return out
+}
+
+// Series returns the posts of a series in reading order. The name may be
+// given in display or slug form.
+func (s *FilesStore) Series(name string) []Post {
+	if strings.TrimSpace(name) == "" {
+		return nil
+	}
+	idx := s.snapshot()
+	ids := idx.series[Slugify(name)]
+	if len(ids) == 0 {
+		return nil
+	}
+	out := make([]Post, len(ids))
+	for i, id := range ids {
+		out[i] = idx.posts[id]
+	}
+	return out
+}
+
+// Related returns up to n posts related to the one with slug, best first.
+// n <= 0 returns all that were kept (at most five).
+func (s *FilesStore) Related(slug string, n int) []Post {
+	idx := s.snapshot()
+	i, ok := idx.bySlug[slug]
+	if !ok {
+		return nil
+	}
+	ids := idx.related[i]
+	if n > 0 && len(ids) > n {
+		ids = ids[:n]
+	}
+	out := make([]Post, len(ids))
+	for k, id := range ids {
+		out[k] = idx.posts[id]
+	}
+	return out
+}
+
+// Archive returns post counts by year and month (copy).
+func (s *FilesStore) Archive() Archive {
+	a := s.snapshot().archive
+	years := make([]ArchiveYear, len(a.Years))
+	for i, y := range a.Years {
+		y.Months = append([]ArchiveMonth(nil), y.Months...)
+		years[i] = y
+	}
+	a.Years = years
+	return a
+}
+
+// ByDate returns posts published in year (and month, unless 0), date desc.
+// Year 0 returns undated posts.
+func (s *FilesStore) ByDate(year int, month time.Month) []Post {
 	var out []Post
-	for _, p := range s.posts {
-		for _, t := range p.Tags {
-			if strings.ToLower(t) == tag {
-				out = append(out, p)
-				break
-			}
+	for _, p := range s.snapshot().posts {
+		if inPeriod(p, year, month) {
+			out = append(out, p)
 		}
 	}
 	return out
 }
 
+// Graph returns the published posts and the wiki links between them (copy).
+func (s *FilesStore) Graph() Graph {
+	g := s.snapshot().graph
+	return Graph{
+		Nodes: append([]GraphNode(nil), g.Nodes...),
+		Edges: append([]GraphEdge(nil), g.Edges...),
+	}
+}
+
+// Search returns posts matching every word of query by prefix, best first.
+// limit <= 0 returns all matches.
+func (s *FilesStore) Search(query string, limit int) []SearchResult {
+	idx := s.snapshot()
+	return idx.search.search(idx.posts, query, limit)
+}
+
 /************ loading ************/
 
 func (s *FilesStore) reload() error {
-	entries, err := os.ReadDir(s.dir)
+	// Fingerprint first: an edit racing with the load is then seen as a
+	// change on the next watch tick instead of being lost.
+	sig, err := s.fingerprint()
+	if err != nil {
+		return err
+	}
+	files, err := listContent(s.dir)
 	if err != nil {
 		return err
 	}
 
-	var posts []Post
-	for _, e := range entries {
-		if e.IsDir() || !strings.HasSuffix(e.Name(), ".md") {
-			continue
+	// listContent walks in lexical order, so everything below that depends
+	// on load order (slug collisions, alias claims) resolves the same way
+	// every time. Hidden posts take part too: a file's URL doesn't depend on whether it
+	// or its neighbours are published, so it is the same in dev and prod and
+	// doesn't move when a scheduled post goes live.
+	var (
+		all     []Post
+		allSrcs []source
+	)
+	now := s.now()
+	for _, f := range files {
+		for _, name := range f.ignored {
+			s.log.Warn("blog_bundle_markdown_ignored",
+				slog.String("file", name),
+				slog.String("bundle", f.rel),
+				slog.String("reason", "only index.md of a bundle is a post"))
 		}
-		p, ok, err := parseFile(filepath.Join(s.dir, e.Name()), s.showDrafts, s.now)
+		p, src, err := s.parseFile(f)
 		if err != nil {
 			return err
 		}
-		if !ok {
+		all = append(all, p)
+		allSrcs = append(allSrcs, src)
+	}
+	s.resolveSlugs(all)
+	s.resolveAliases(all)
+
+	// Rendering waits for final slugs: bundle links point at /blog/{slug}/,
+	// wiki links at /blog/{slug}.
+	bundles := make(map[string]fs.FS)
+	finder := newPostFinder(all)
+	for i := range all {
+		if allSrcs[i].bundle != "" {
+			bundles[all[i].Slug] = assetFS{os.DirFS(allSrcs[i].bundle)}
+		}
+		// Shortcode bodies are parsed as they render; they get the same pass.
+		links := func(doc ast.Node) error { return s.resolveWikiLinks(all, i, doc, finder, now) }
+		if err := links(allSrcs[i].doc); err != nil {
+			return err
+		}
+		if err := s.render(&all[i], allSrcs[i], bundles[all[i].Slug], links); err != nil {
+			return err
+		}
+	}
+
+	idx := s.buildIndex(all, bundles, sig, now)
+	s.mu.Lock()
+	s.idx = idx
+	s.mu.Unlock()
+	return nil
+}
+
+// visible reports whether p is published at now: not a draft and not dated
+// in the future, unless drafts are shown.
+func (s *FilesStore) visible(p Post, now time.Time) bool {
+	return s.showDrafts || (!p.Draft && (p.Date.IsZero() || !p.Date.After(now)))
+}
+
+// buildIndex indexes the posts of all that are visible at now; the rest are
+// kept for Preview. all is not modified, so the same posts can be indexed
+// again when the next scheduled one comes due (see snapshot).
+func (s *FilesStore) buildIndex(all []Post, bundles map[string]fs.FS, sig string, now time.Time) *index {
+	var posts, hidden []Post
+	var next time.Time
+	for _, p := range all {
+		p.Backlinks = nil // filled in per index, from visible posts only
+		// Series navigation is filled in per index; never share it.
+		if p.Series != nil {
+			nav := SeriesNav{Name: p.Series.Name, Slug: p.Series.Slug, Order: p.Series.Order}
+			p.Series = &nav
+		}
+		if s.visible(p, now) {
+			posts = append(posts, p)
 			continue
 		}
-		posts = append(posts, p)
+		p.Series = nil // not part of the published series
+		hidden = append(hidden, p)
+		if !p.Draft && (next.IsZero() || p.Date.Before(next)) {
+			next = p.Date
+		}
+	}
+	aliases := make(map[string]string)
+	for _, p := range posts {
+		for _, a := range p.Aliases {
+			aliases[a] = p.Slug
+		}
 	}
 
 	// Sort newest first; zero dates go last.
@@ -126,129 +442,417 @@ func (s *FilesStore) reload() error {
 		return di.After(dj)
 	})
 
-	// Ensure slug uniqueness by appending -2, -3, ...
-	seen := make(map[string]struct{}, len(posts))
+	idx := &index{
+		posts:   posts,
+		series:  indexSeries(posts),
+		bySlug:  make(map[string]int, len(posts)),
+		aliases: aliases,
+		bundles: bundles,
+		hidden:  make(map[string]Post, len(hidden)),
+		all:     all,
+		next:    next,
+		sig:     sig,
+	}
+	for _, p := range hidden {
+		idx.hidden[p.Slug] = p
+	}
+	for i, p := range posts {
+		idx.bySlug[p.Slug] = i
+	}
+	idx.tags, idx.byTag = indexTags(posts)
+	idx.search = buildSearchIndex(posts)
+	idx.related = buildRelated(posts, idx.search.text)
+	idx.archive = buildArchive(posts)
+	idx.graph = indexLinks(posts, idx.bySlug)
+	return idx
+}
+
+// reservedSlugs are the names the site routes itself under /blog/ (see
+// cmd/web/routes.go). A post using one could never be reached.
+var reservedSlugs = []string{
+	"page", "search", "tags", "series", "archive",
+	"syntax.css", "graph.json", "feed.atom", "feed.xml", "feed.json",
+}
+
+// resolveSlugs keeps slugs unique. The first file (by name) keeps a
+// contested slug; later ones get -2, -3, ... and a warning, since their URL
+// now depends on another file. Fix by setting `slug:` explicitly. Reserved
+// route names count as taken.
+func (s *FilesStore) resolveSlugs(posts []Post) {
+	owner := make(map[string]string, len(posts)+len(reservedSlugs)) // slug -> source file
+	for _, r := range reservedSlugs {
+		owner[r] = "route /blog/" + r
+	}
 	for i := range posts {
 		base := posts[i].Slug
 		slug := base
-		k := 2
-		for {
-			if _, exists := seen[slug]; !exists {
-				break
-			}
+		for k := 2; owner[slug] != ""; k++ {
 			slug = fmt.Sprintf("%s-%d", base, k)
-			k++
+		}
+		if slug != base {
+			s.log.Warn("blog_duplicate_slug",
+				slog.String("slug", base),
+				slog.String("file", posts[i].Source),
+				slog.String("kept_by", owner[base]),
+				slog.String("using", slug))
 		}
 		posts[i].Slug = slug
-		seen[slug] = struct{}{}
+		owner[slug] = posts[i].Source
+	}
+}
+
+// resolveAliases drops, with a warning, every alias that is some post's
+// real slug or a reserved route name, or that an earlier file already
+// claimed. Hidden posts count, so publishing one never takes its slug or
+// aliases back from another post. Post.Aliases is left holding only the
+// aliases in effect; buildIndex serves those of visible posts.
+func (s *FilesStore) resolveAliases(posts []Post) {
+	slugs := make(map[string]bool, len(posts))
+	for _, p := range posts {
+		slugs[p.Slug] = true
 	}
+	for _, r := range reservedSlugs {
+		slugs[r] = true
+	}
+	aliases := make(map[string]string)
+	for i := range posts {
+		var kept []string
+		for _, a := range posts[i].Aliases {
+			switch {
+			case slugs[a]:
+				s.log.Warn("blog_alias_conflict", slog.String("alias", a),
+					slog.String("file", posts[i].Source), slog.String("reason", "is a post slug or route"))
+			case aliases[a] != "" && aliases[a] != posts[i].Slug:
+				s.log.Warn("blog_alias_conflict", slog.String("alias", a),
+					slog.String("file", posts[i].Source), slog.String("reason", "claimed by "+aliases[a]))
+			case aliases[a] == "":
+				aliases[a] = posts[i].Slug
+				kept = append(kept, a)
+			}
+		}
+		posts[i].Aliases = kept
+	}
+}
 
-	s.posts = posts
-	s.bySlug = make(map[string]int, len(posts))
+// indexTags groups posts by tag slug. The display name of a tag is the
+// spelling used by the newest post carrying it.
+func indexTags(posts []Post) ([]Tag, map[string][]int) {
+	byTag := make(map[string][]int)
+	var tags []Tag
+	pos := make(map[string]int)
 	for i, p := range posts {
-		s.bySlug[p.Slug] = i
+		for _, t := range p.Tags {
+			slug := TagSlug(t)
+			byTag[slug] = append(byTag[slug], i)
+			if j, ok := pos[slug]; ok {
+				tags[j].Count++
+				continue
+			}
+			pos[slug] = len(tags)
+			tags = append(tags, Tag{Name: t, Slug: slug, Count: 1})
+		}
 	}
-	return nil
+	sort.SliceStable(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Slug < tags[j].Slug
+	})
+	return tags, byTag
 }
 
-/************ parsing ************/
+/************ watching ************/
+
+// Watch polls the content directory every interval and reloads the index
+// when a Markdown file is added, edited or removed. A failed reload is
+// logged and the previous index keeps serving. Watch blocks until ctx is
+// done and returns ctx.Err().
+func (s *FilesStore) Watch(ctx context.Context, interval time.Duration) error {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	var failed string // fingerprint of the last state that failed to load
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+
+		fp, err := s.fingerprint()
+		if err != nil {
+			s.log.Warn("blog_watch", slog.String("dir", s.dir), slog.Any("err", err))
+			continue
+		}
+		// Report a broken file once rather than on every tick.
+		if fp == s.snapshot().sig || fp == failed {
+			continue
+		}
+		if err := s.reload(); err != nil {
+			failed = fp
+			s.log.Error("blog_reload", slog.String("dir", s.dir), slog.Any("err", err))
+			continue
+		}
+		failed = ""
+		s.log.Info("blog_reload", slog.String("dir", s.dir), slog.Int("posts", len(s.snapshot().posts)))
+	}
+}
+
+// fingerprint summarizes path, size and mtime of every post source and
+// bundle asset. Assets count because rendering reads them: image sizes and
+// variant URLs are baked into the post HTML (see WithImages).
+func (s *FilesStore) fingerprint() (string, error) {
+	files, err := listContent(s.dir)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	stat := func(path, rel string) {
+		info, err := os.Stat(path)
+		if err != nil {
+			// Removed between the walk and Stat; the next tick will settle it.
+			return
+		}
+		fmt.Fprintf(&b, "%s|%d|%d\n", rel, info.Size(), info.ModTime().UnixNano())
+	}
+	for _, f := range files {
+		stat(f.path, f.rel)
+		if f.bundle == "" {
+			continue
+		}
+		assets := assetFS{os.DirFS(f.bundle)}
+		dir := path.Dir(f.rel)
+		err := fs.WalkDir(assets, ".", func(name string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			stat(filepath.Join(f.bundle, filepath.FromSlash(name)), path.Join(dir, name))
+			return nil
+		})
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
 
-var md = goldmark.New() // customize later with extensions if needed
+/************ parsing ************/
 
-type frontMatter struct {
-	Title   string   `yaml:"title"`
-	Slug    string   `yaml:"slug"`
-	Date    string   `yaml:"date"`
-	Tags    []string `yaml:"tags"`
-	Draft   bool     `yaml:"draft"`
-	Summary string   `yaml:"summary"`
+// source is a parsed post body awaiting render.
+type source struct {
+	doc    ast.Node
+	src    []byte
+	bundle string          // bundle directory; "" for plain posts
+	calls  []shortcodeCall // indexed by the placeholders in src
 }
 
-// parseFile reads a .md file, parses front matter, renders Markdown.
-// Returns (Post, true, nil) when included;
-// (zero, false, nil) when excluded due to draft/future;
-// (zero, false, err) on error.
-func parseFile(path string, showDrafts bool, now func() time.Time) (Post, bool, error) {
+// parseFile reads a .md file, parses front matter and Markdown. HTML is
+// filled in later by render, once slugs are final. Drafts and future posts
+// are returned too; visibility is decided per index (see buildIndex).
+func (s *FilesStore) parseFile(f contentFile) (Post, source, error) {
 	var zero Post
+	path := f.path
 
 	b, err := os.ReadFile(path)
 	if err != nil {
-		return zero, false, fmt.Errorf("read %s: %w", filepath.Base(path), err)
+		return zero, source{}, fmt.Errorf("read %s: %w", f.rel, err)
 	}
 
-	fmBytes, body := splitFrontMatter(b)
-	var fm frontMatter
-	if len(fmBytes) > 0 {
-		if err := yaml.Unmarshal(fmBytes, &fm); err != nil {
-			return zero, false, fmt.Errorf("front matter %s: %w", filepath.Base(path), err)
-		}
+	fm, body, err := parseFrontMatter(b)
+	if err != nil {
+		return zero, source{}, fmt.Errorf("front matter %s: %w", f.rel, err)
 	}
 
+	// A bundle is named by its directory, a plain post by its file.
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if f.bundle != "" {
+		name = filepath.Base(f.bundle)
+	}
 	title := strings.TrimSpace(fm.Title)
 	if title == "" {
-		title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		title = name
 	}
 
 	slug := fm.Slug
-	if slug == "" {
+	switch {
+	case slug != "":
+	case f.bundle != "":
+		slug = Slugify(name)
+	default:
 		slug = Slugify(title)
 	}
 
-	date, _ := parseDate(fm.Date)
-
-	// Filter drafts/future posts unless showing drafts.
-	if !showDrafts {
-		if fm.Draft {
-			return zero, false, nil
-		}
-		if !date.IsZero() && date.After(now()) {
-			return zero, false, nil
+	date, err := parseDate(fm.Date)
+	if err != nil {
+		return zero, source{}, fieldError(f.rel, "date", err)
+	}
+	updatedField, updatedRaw := "updated", fm.Updated
+	if updatedRaw == "" && fm.LastMod != "" {
+		updatedField, updatedRaw = "lastmod", fm.LastMod // Hugo's name for it
+	}
+	updated, err := parseDate(updatedRaw)
+	if err != nil {
+		return zero, source{}, fieldError(f.rel, updatedField, err)
+	}
+	authors, err := s.resolveAuthors(fm.Authors)
+	if err != nil {
+		return zero, source{}, fieldError(f.rel, "authors", err)
+	}
+	canonical, err := checkCanonical(fm.Canonical)
+	if err != nil {
+		return zero, source{}, fieldError(f.rel, "canonical", err)
+	}
+	lang := strings.TrimSpace(fm.Lang)
+	if lang != "" && !langRE.MatchString(lang) {
+		return zero, source{}, fieldError(f.rel, "lang", fmt.Errorf("%q is not a language tag like en or pt-BR", lang))
+	}
+	var cover *Image
+	if img := strings.TrimSpace(fm.Cover.Image); img != "" {
+		cover = &Image{Src: img, Alt: strings.TrimSpace(fm.Cover.Alt)}
+		if cover.Alt == "" {
+			s.log.Warn("blog_cover_alt", slog.String("file", f.rel), slog.String("msg", "cover has no alt text"))
 		}
+	} else if fm.Cover.Alt != "" {
+		return zero, source{}, fieldError(f.rel, "cover", errors.New("alt given without image"))
 	}
 
-	var out bytes.Buffer
-	if err := md.Convert(body, &out); err != nil {
-		return zero, false, fmt.Errorf("markdown %s: %w", filepath.Base(path), err)
+	// Shortcodes become placeholders now and are rendered with the post.
+	sc := shortcodeScanner{file: f.rel, known: s.hasShortcode}
+	line := 1 + bytes.Count(b, []byte("\n")) - bytes.Count(body, []byte("\n"))
+	if body, err = sc.expand(body, line); err != nil {
+		return zero, source{}, err
+	}
+
+	// The excerpt marker is for us, not the reader.
+	hasMore := bytes.Contains(body, moreMarker)
+	rendered := body
+	if hasMore {
+		rendered = bytes.Replace(body, moreMarker, nil, 1)
+	}
+
+	// Parse and render separately so the AST can feed the TOC and stats.
+	doc := s.md.Parser().Parse(text.NewReader(rendered))
+	var toc []TOCEntry
+	if fm.TOC == nil || *fm.TOC {
+		toc = extractTOC(doc, rendered, s.tocMin, s.tocMax)
+	}
+	words := countWords(doc, rendered) + shortcodeWords(sc.calls)
+	summary := strings.TrimSpace(fm.Summary)
+	if summary == "" {
+		summary = excerpt(s.md.Parser(), doc, body)
+	}
+
+	var series *SeriesNav
+	if name := strings.Join(strings.Fields(fm.Series), " "); name != "" {
+		series = &SeriesNav{Name: name, Slug: Slugify(name), Order: fm.SeriesOrder}
 	}
 
 	post := Post{
 		Title:   title,
 		Slug:    slug,
 		Date:    date,
-		Tags:    fm.Tags,
+		Updated: updated,
+		Tags:    cleanTags(fm.Tags),
 		Draft:   fm.Draft,
-		Summary: fm.Summary,
-		HTML:    template.HTML(out.String()),
+		Summary: summary,
+		TOC:     toc,
+		Series:  series,
+		Aliases: cleanAliases(fm.Aliases),
+		Source:  f.rel,
+
+		Authors:     authors,
+		Cover:       cover,
+		Canonical:   canonical,
+		Description: strings.TrimSpace(fm.Description),
+		Lang:        lang,
+
+		WordCount:   words,
+		ReadingTime: readingTime(words),
 	}
-	return post, true, nil
+	return post, source{doc: doc, src: rendered, bundle: f.bundle, calls: sc.calls}, nil
+}
+
+// hasShortcode reports whether a template is defined for the shortcode.
+func (s *FilesStore) hasShortcode(name string) bool {
+	return s.shortcodes != nil && s.shortcodes.Lookup(shortcodeTemplate(name)) != nil
 }
 
-// splitFrontMatter returns (yaml, body) if file begins with a '---' line; otherwise (nil, b).
-// Supports the common YAML front matter block delimited by:
-// ---\n
-// ...yaml...
-// ---\n
-func splitFrontMatter(b []byte) (yamlPart, body []byte) {
-	s := string(b)
-	// Normalize line endings lightly by working line-by-line.
-	lines := strings.Split(s, "\n")
-	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
-		return nil, b
+// render fills in p.HTML. assets is non-nil for page bundles, whose relative
+// links are rewritten to the post's URL.
+func (s *FilesStore) render(p *Post, src source, assets fs.FS, links func(ast.Node) error) error {
+	base := "/blog/" + p.Slug + "/"
+	if assets != nil {
+		rewriteBundleLinks(src.doc, base, assets)
+		if p.Cover != nil {
+			if ref, _, ok := bundleRef(p.Cover.Src); ok {
+				p.Cover = &Image{Src: base + ref, Alt: p.Cover.Alt}
+			}
+		}
+	}
+	var out bytes.Buffer
+	if err := s.md.Renderer().Render(&out, src.src, src.doc); err != nil {
+		return fmt.Errorf("markdown %s: %w", p.Source, err)
+	}
+	html := out.Bytes()
+	if len(src.calls) > 0 {
+		r := shortcodeRenderer{
+			t:     s.shortcodes,
+			md:    s.md,
+			calls: src.calls,
+			file:  p.Source,
+			links: links,
+			data:  Shortcode{Post: p.Ref(), Site: s.site, base: base, assets: assets},
+		}
+		var err error
+		if html, err = r.fill(html); err != nil {
+			return err
+		}
+	}
+	if s.images != nil {
+		var err error
+		if html, err = s.images.RewriteHTML(html, base, assets); err != nil {
+			return fmt.Errorf("images %s: %w", p.Source, err)
+		}
 	}
-	// Find closing '---' on its own line.
-	end := -1
-	for i := 1; i < len(lines); i++ {
-		if strings.TrimSpace(lines[i]) == "---" {
-			end = i
-			break
+	p.HTML = template.HTML(html)
+	return nil
+}
+
+// cleanAliases accepts old slugs or old paths ("/blog/old-title/") and
+// returns bare, de-duplicated slugs.
+func cleanAliases(in []string) []string {
+	var out []string
+	seen := make(map[string]bool, len(in))
+	for _, a := range in {
+		a = strings.Trim(strings.TrimSpace(a), "/")
+		a = strings.TrimPrefix(a, "blog/")
+		if a == "" || strings.Contains(a, "/") || seen[a] {
+			continue
 		}
+		seen[a] = true
+		out = append(out, a)
 	}
-	if end == -1 {
-		return nil, b
+	return out
+}
+
+// cleanTags trims tags, drops empty ones and de-duplicates by TagSlug,
+// keeping the first spelling.
+func cleanTags(in []string) []string {
+	var out []string
+	seen := make(map[string]struct{}, len(in))
+	for _, t := range in {
+		t = strings.Join(strings.Fields(t), " ")
+		if t == "" {
+			continue
+		}
+		slug := TagSlug(t)
+		if _, dup := seen[slug]; dup {
+			continue
+		}
+		seen[slug] = struct{}{}
+		out = append(out, t)
 	}
-	yamlPart = []byte(strings.Join(lines[1:end], "\n"))
-	body = []byte(strings.Join(lines[end+1:], "\n"))
-	return yamlPart, body
+	return out
 }
 
 func parseDate(s string) (time.Time, error) {
@@ -262,16 +866,48 @@ func parseDate(s string) (time.Time, error) {
 		"2006-01-02",
 		"2006-01-02 15:04",
 		"2006-01-02T15:04",
+		"2006-01-02T15:04:05", // TOML local date-time
 	}
-	var last error
 	for _, f := range formats {
 		if t, err := time.Parse(f, s); err == nil {
 			return t, nil
-		} else {
-			last = err
 		}
 	}
-	return time.Time{}, last
+	return time.Time{}, fmt.Errorf("cannot parse %q (want 2006-01-02, 2006-01-02 15:04 or RFC 3339)", s)
+}
+
+// fieldError reports an invalid front matter value, naming file and field.
+func fieldError(file, field string, err error) error {
+	return fmt.Errorf("front matter %s: %s: %w", file, field, err)
+}
+
+// langRE loosely matches BCP 47 language tags ("en", "pt-BR", "zh-Hant-TW").
+var langRE = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// checkCanonical accepts "" or an absolute http(s) URL.
+func checkCanonical(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%q is not an absolute http(s) URL", raw)
+	}
+	return raw, nil
+}
+
+// resolveAuthors maps author ids to the configured authors.
+func (s *FilesStore) resolveAuthors(ids []string) ([]Author, error) {
+	var out []Author
+	for _, id := range ids {
+		a, ok := s.authors[strings.TrimSpace(id)]
+		if !ok {
+			return nil, fmt.Errorf("unknown author %q (add it to the site config)", id)
+		}
+		out = append(out, a)
+	}
+	return out, nil
 }
 
 // Slugify converts a string into a URL-safe identifier.
@@ -300,3 +936,11 @@ func Slugify(s string) string {
 	return out
 }
 
+// TagSlug returns the canonical URL form of a tag. Tags that differ only in
+// case, spacing or punctuation ("Go Lang", "go-lang") share a slug.
+func TagSlug(tag string) string {
+	if strings.TrimSpace(tag) == "" {
+		return ""
+	}
+	return Slugify(tag)
+}
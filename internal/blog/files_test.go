@@ -1,8 +1,13 @@
 package blog
 
 import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -78,6 +83,56 @@ x`)
 	}
 }
 
+func TestFilesStore_PreviewHiddenPosts(t *testing.T) {
+	td := t.TempDir()
+	write(t, td, "a-draft.md", "---\ntitle: Launch\ndraft: true\nseries: S\n---\ndraft")
+	write(t, td, "b-live.md", "---\ntitle: Launch\ndate: 2025-08-01\nseries: S\n---\nlive")
+	write(t, td, "c-later.md", "---\ntitle: Later\ndate: 2025-09-01\n---\nlater")
+
+	now := time.Date(2025, 8, 15, 0, 0, 0, 0, time.UTC)
+	s, err := NewFilesStore(td,
+		WithNow(func() time.Time { return now }),
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Slugs go by file name whether or not a post is published, so the
+	// draft sorting first keeps "launch".
+	if p, ok := s.BySlug("launch-2"); !ok || p.Source != "b-live.md" {
+		t.Fatalf("launch-2 = %+v, %v; want the published post", p, ok)
+	}
+	if _, ok := s.BySlug("launch"); ok {
+		t.Fatal("BySlug returned a draft")
+	}
+	if len(s.All()) != 1 || len(s.Series("S")) != 1 {
+		t.Fatalf("hidden posts leaked into listings")
+	}
+
+	draft, ok := s.Preview("launch")
+	if !ok || !draft.Draft || draft.HTML == "" || draft.Series != nil {
+		t.Fatalf("Preview(launch) = %+v, %v", draft, ok)
+	}
+	if _, ok := s.Preview("later"); !ok {
+		t.Fatal("future post not previewable")
+	}
+	if _, ok := s.Preview("launch-2"); ok {
+		t.Fatal("Preview returned a published post")
+	}
+
+	// Showing drafts (dev) gives every file the same URL as in prod.
+	dev, err := NewFilesStore(td, WithDrafts(true),
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for slug, file := range map[string]string{"launch": "a-draft.md", "launch-2": "b-live.md", "later": "c-later.md"} {
+		if p, ok := dev.BySlug(slug); !ok || p.Source != file {
+			t.Fatalf("dev %s = %q, %v; want %s", slug, p.Source, ok, file)
+		}
+	}
+}
+
 func TestFilesStore_DuplicateSlugs(t *testing.T) {
 	td := t.TempDir()
 	write(t, td, "one.md", `---
@@ -99,5 +154,386 @@ y`)
 	if all[0].Slug == all[1].Slug {
 		t.Fatalf("slugs not unique: %q", all[0].Slug)
 	}
+	// The first file by name keeps the slug, regardless of date.
+	if p, _ := s.BySlug("same-title"); p.Source != "one.md" {
+		t.Fatalf("same-title owned by %q, want one.md", p.Source)
+	}
+	if p, _ := s.BySlug("same-title-2"); p.Source != "two.md" {
+		t.Fatalf("same-title-2 owned by %q, want two.md", p.Source)
+	}
+}
+
+func TestFilesStore_ReservedSlugs(t *testing.T) {
+	td := t.TempDir()
+	write(t, td, "search.md", "---\ntitle: Search\n---\nx")
+	write(t, td, "feed.md", "---\ntitle: Feed\nslug: feed.atom\naliases: [tags, old-feed]\n---\ny")
+
+	s, err := NewFilesStore(td, WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for slug, file := range map[string]string{"search-2": "search.md", "feed.atom-2": "feed.md"} {
+		if p, ok := s.BySlug(slug); !ok || p.Source != file {
+			t.Fatalf("%s = %q, %v; want %s", slug, p.Source, ok, file)
+		}
+	}
+	for _, slug := range []string{"search", "feed.atom"} {
+		if _, ok := s.BySlug(slug); ok {
+			t.Fatalf("post took the route name %q", slug)
+		}
+	}
+	if _, ok := s.Alias("tags"); ok {
+		t.Fatal("alias took the route name tags")
+	}
+	if to, _ := s.Alias("old-feed"); to != "feed.atom-2" {
+		t.Fatalf("Alias(old-feed) = %q", to)
+	}
+}
+
+func TestFilesStore_Aliases(t *testing.T) {
+	td := t.TempDir()
+	write(t, td, "a.md", "---\ntitle: New Name\naliases: [old-name, /blog/older-name/, b-post]\n---\na")
+	write(t, td, "b.md", "---\ntitle: B Post\naliases: [old-name, bee]\n---\nb")
+
+	s, err := NewFilesStore(td, WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for alias, want := range map[string]string{
+		"old-name":   "new-name", // a.md claimed it first
+		"older-name": "new-name",
+		"bee":        "b-post",
+	} {
+		if got, ok := s.Alias(alias); !ok || got != want {
+			t.Errorf("Alias(%q) = %q, %v; want %q", alias, got, ok, want)
+		}
+	}
+	if _, ok := s.Alias("b-post"); ok {
+		t.Errorf("a real slug must not become an alias")
+	}
+	a, _ := s.BySlug("new-name")
+	if want := []string{"old-name", "older-name"}; strings.Join(a.Aliases, ",") != strings.Join(want, ",") {
+		t.Errorf("a.Aliases = %v, want %v", a.Aliases, want)
+	}
+}
+
+
+func TestFilesStore_AliasesCannotTakeHiddenSlugs(t *testing.T) {
+	td := t.TempDir()
+	write(t, td, "a.md", "---\ntitle: A\ndate: 2025-08-01\naliases: [draft, soon, gone]\n---\na")
+	write(t, td, "draft.md", "---\ntitle: Draft\ndraft: true\n---\nd")
+	write(t, td, "soon.md", "---\ntitle: Soon\ndate: 2025-08-01T12:00:00Z\naliases: [soon-old]\n---\ns")
+
+	clock := time.Date(2025, 8, 1, 10, 0, 0, 0, time.UTC)
+	var logs bytes.Buffer
+	s, err := NewFilesStore(td,
+		WithNow(func() time.Time { return clock }),
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, alias := range []string{"draft", "soon", "soon-old"} {
+		if to, ok := s.Alias(alias); ok {
+			t.Errorf("Alias(%q) = %q, want none", alias, to)
+		}
+	}
+	if to, _ := s.Alias("gone"); to != "a" {
+		t.Errorf("Alias(gone) = %q, want a", to)
+	}
+	if n := strings.Count(logs.String(), "blog_alias_conflict"); n != 2 {
+		t.Fatalf("%d alias warnings at load, want 2:\n%s", n, logs.String())
+	}
+
+	// Going live re-indexes without warning again.
+	logs.Reset()
+	clock = time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)
+	if _, ok := s.BySlug("soon"); !ok {
+		t.Fatal("scheduled post not live")
+	}
+	if to, _ := s.Alias("soon-old"); to != "soon" {
+		t.Errorf("Alias(soon-old) = %q, want soon", to)
+	}
+	if strings.Contains(logs.String(), "blog_alias_conflict") {
+		t.Fatalf("re-index warned again:\n%s", logs.String())
+	}
+}
+
+func TestFilesStore_WatchReloads(t *testing.T) {
+	td := t.TempDir()
+	write(t, td, "a.md", `---
+title: "A"
+date: 2025-08-01
+---
+a`)
+
+	quiet := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s, err := NewFilesStore(td, WithLogger(quiet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Watch(ctx, 10*time.Millisecond)
+
+	waitFor := func(what string, cond func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", what)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	// Added post shows up.
+	write(t, td, "b.md", `---
+title: "B"
+date: 2025-08-02
+---
+b`)
+	waitFor("new post", func() bool { return len(s.All()) == 2 })
+
+	// A broken file keeps the previous index serving.
+	write(t, td, "broken.md", "---\ntitle: [unclosed\n---\nx")
+	time.Sleep(50 * time.Millisecond)
+	if got := len(s.All()); got != 2 {
+		t.Fatalf("posts=%d after parse error, want 2", got)
+	}
+
+	// Removing files is picked up too.
+	for _, name := range []string{"broken.md", "b.md"} {
+		if err := os.Remove(filepath.Join(td, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitFor("removed post", func() bool { return len(s.All()) == 1 })
+	if _, ok := s.BySlug("b"); ok {
+		t.Fatalf("BySlug(b) still found after removal")
+	}
+}
+
+func TestFilesStore_TagsNormalized(t *testing.T) {
+	td := t.TempDir()
+	write(t, td, "a.md", `---
+title: "A"
+date: 2025-08-01
+tags: ["Go Lang", " web ", "go-lang", ""]
+---
+a`)
+	write(t, td, "b.md", `---
+title: "B"
+date: 2025-08-02
+tags: ["GO LANG"]
+---
+b`)
+
+	s, err := NewFilesStore(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := s.BySlug("a")
+	if len(a.Tags) != 2 || a.Tags[0] != "Go Lang" || a.Tags[1] != "web" {
+		t.Fatalf("tags not cleaned: %q", a.Tags)
+	}
+
+	tags := s.Tags()
+	if len(tags) != 2 {
+		t.Fatalf("tags=%d, want 2: %+v", len(tags), tags)
+	}
+	if tags[0].Slug != "go-lang" || tags[0].Count != 2 || tags[0].Name != "GO LANG" {
+		t.Fatalf("top tag = %+v, want go-lang x2 named by newest post", tags[0])
+	}
+
+	for _, q := range []string{"go-lang", "Go Lang", "go lang"} {
+		if got := len(s.ByTag(q)); got != 2 {
+			t.Fatalf("ByTag(%q)=%d, want 2", q, got)
+		}
+	}
+}
+
+func TestFilesStore_Page(t *testing.T) {
+	td := t.TempDir()
+	for _, d := range []string{"01", "02", "03"} {
+		write(t, td, d+".md", "---\ntitle: \"P"+d+"\"\ndate: 2025-08-"+d+"\n---\nx")
+	}
+	s, err := NewFilesStore(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, total := s.Page(1, 2)
+	if total != 3 || len(got) != 2 || got[0].Title != "P03" || got[1].Title != "P02" {
+		t.Fatalf("page 1 = %d posts (total %d)", len(got), total)
+	}
+	got, _ = s.Page(2, 2)
+	if len(got) != 1 || got[0].Title != "P01" {
+		t.Fatalf("page 2 = %+v", got)
+	}
+	if got, _ := s.Page(3, 2); len(got) != 0 {
+		t.Fatalf("page 3 = %d posts, want 0", len(got))
+	}
+}
+
+func TestFilesStore_Search(t *testing.T) {
+	td := t.TempDir()
+	write(t, td, "conc.md", `---
+title: "Concurrency in Go"
+date: 2025-08-01
+tags: [go]
+---
+Channels & goroutines make concurrent code readable.`)
+	write(t, td, "web.md", `---
+title: "Web servers"
+date: 2025-08-02
+summary: "Serving HTTP with the standard library"
+---
+A tour of net/http, with a nod to Go concurrency.`)
+
+	s, err := NewFilesStore(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Prefix match; title hit outranks body hit.
+	got := s.Search("concur", 0)
+	if len(got) != 2 || got[0].Post.Slug != "concurrency-in-go" {
+		t.Fatalf("Search(concur) = %+v", got)
+	}
+	if !strings.Contains(string(got[1].Snippet), "<mark>concurrency.</mark>") {
+		t.Fatalf("snippet not highlighted: %q", got[1].Snippet)
+	}
+	if !strings.Contains(string(got[0].Snippet), "&amp;") {
+		t.Fatalf("snippet not escaped: %q", got[0].Snippet)
+	}
+
+	// Every term must match.
+	if got := s.Search("go http", 0); len(got) != 1 || got[0].Post.Slug != "web-servers" {
+		t.Fatalf("Search(go http) = %+v", got)
+	}
+	if got := s.Search("rust", 0); len(got) != 0 {
+		t.Fatalf("Search(rust) = %d results, want 0", len(got))
+	}
+	if got := s.Search("go", 1); len(got) != 1 {
+		t.Fatalf("limit not applied: %d", len(got))
+	}
+}
+
+func TestFilesStore_ScheduledPostGoesLive(t *testing.T) {
+	td := t.TempDir()
+	write(t, td, "now.md", "---\ntitle: Now\ndate: 2025-08-01T09:00:00Z\ntags: [go]\n---\nnow")
+	write(t, td, "soon.md", "---\ntitle: Soon\ndate: 2025-08-01T12:00:00Z\ntags: [go]\n---\nsoon")
+	write(t, td, "later.md", "---\ntitle: Later\ndate: 2025-08-02T12:00:00Z\n---\nlater")
+	write(t, td, "draft.md", "---\ntitle: Draft\ndate: 2025-07-01\ndraft: true\n---\ndraft")
+
+	clock := time.Date(2025, 8, 1, 10, 0, 0, 0, time.UTC)
+	s, err := NewFilesStore(td,
+		WithNow(func() time.Time { return clock }),
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	titles := func() string {
+		var out []string
+		for _, p := range s.All() {
+			out = append(out, p.Title)
+		}
+		return strings.Join(out, ",")
+	}
+
+	if got := titles(); got != "Now" {
+		t.Fatalf("before: %s, want Now", got)
+	}
+	if _, ok := s.Preview("soon"); !ok {
+		t.Fatal("scheduled post not previewable")
+	}
+
+	clock = time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)
+	if got := titles(); got != "Soon,Now" {
+		t.Fatalf("at publish time: %s, want Soon,Now", got)
+	}
+	if _, ok := s.BySlug("soon"); !ok {
+		t.Fatal("published post not found by slug")
+	}
+	if _, ok := s.Preview("soon"); ok {
+		t.Fatal("published post still in previews")
+	}
+	if len(s.ByTag("go")) != 2 || len(s.Search("soon", 0)) != 1 {
+		t.Fatal("tag and search indexes not refreshed")
+	}
+
+	clock = clock.AddDate(1, 0, 0)
+	if got := titles(); got != "Later,Soon,Now" {
+		t.Fatalf("much later: %s, want Later,Soon,Now (drafts stay hidden)", got)
+	}
+}
+
+func TestFilesStore_RichFrontMatter(t *testing.T) {
+	td := t.TempDir()
+	write(t, td, "a.md", `---
+title: Cross-posted
+date: 2025-08-01
+updated: 2025-08-03T10:00:00Z
+authors: [ana, bo]
+cover: {image: https://cdn.example.com/c.jpg, alt: A cover}
+canonical: https://dev.to/ana/cross-posted
+description: For search engines.
+lang: pt-BR
+---
+body`)
+	write(t, td, "b.md", "---\ntitle: Short Cover\ncover: /static/img/b.jpg\n---\nb")
+
+	s, err := NewFilesStore(td,
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		WithAuthors([]Author{
+			{ID: "ana", Name: "Ana", Links: []Link{{Label: "Site", URL: "https://ana.dev"}}},
+			{ID: "bo", Name: "Bo"},
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := s.BySlug("cross-posted")
+	if len(a.Authors) != 2 || a.Authors[0].Name != "Ana" || a.Authors[1].ID != "bo" {
+		t.Fatalf("authors = %+v", a.Authors)
+	}
+	if a.Cover == nil || a.Cover.Src != "https://cdn.example.com/c.jpg" || a.Cover.Alt != "A cover" {
+		t.Fatalf("cover = %+v", a.Cover)
+	}
+	if a.Canonical != "https://dev.to/ana/cross-posted" || a.Lang != "pt-BR" || a.Updated.Day() != 3 {
+		t.Fatalf("post = %+v", a)
+	}
+	if a.MetaDescription() != "For search engines." {
+		t.Fatalf("description = %q", a.MetaDescription())
+	}
+	b, _ := s.BySlug("short-cover")
+	if b.Cover == nil || b.Cover.Src != "/static/img/b.jpg" {
+		t.Fatalf("scalar cover = %+v", b.Cover)
+	}
+	if b.MetaDescription() != "b" {
+		t.Fatalf("description should fall back to summary, got %q", b.MetaDescription())
+	}
 }
 
+func TestFilesStore_InvalidFrontMatterNamesFileAndField(t *testing.T) {
+	cases := map[string]string{
+		"date":      "date: last tuesday",
+		"updated":   "updated: 2025-13-40",
+		"authors":   "authors: [nobody]",
+		"canonical": "canonical: /relative/path",
+		"lang":      "lang: english please",
+		"cover":     "cover: {alt: no image}",
+	}
+	for field, line := range cases {
+		t.Run(field, func(t *testing.T) {
+			td := t.TempDir()
+			write(t, td, "bad.md", "---\ntitle: Bad\n"+line+"\n---\nx")
+			_, err := NewFilesStore(td)
+			if err == nil {
+				t.Fatal("want error")
+			}
+			if !strings.Contains(err.Error(), "bad.md: "+field+":") {
+				t.Fatalf("error %q does not name file and field", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// internal/blog/frontmatter.go
+package blog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+/*
+Front matter may be written in any of the formats Hugo and friends accept:
+
+	---            +++              {
+	title: YAML    title = "TOML"     "title": "JSON"
+	---            +++              }
+
+All three decode into the same frontMatter, so field names and validation
+are shared. Files may use CRLF line endings and start with a UTF-8 BOM.
+*/
+
+type frontMatter struct {
+	Title   string   `yaml:"title"`
+	Slug    string   `yaml:"slug"`
+	Date    string   `yaml:"date"`
+	Updated string   `yaml:"updated"`
+	LastMod string   `yaml:"lastmod"` // Hugo's spelling of updated
+	Tags    []string `yaml:"tags"`
+	Draft   bool     `yaml:"draft"`
+	Summary string   `yaml:"summary"`
+	TOC     *bool    `yaml:"toc"` // nil means enabled
+	Aliases []string `yaml:"aliases"`
+
+	Series      string `yaml:"series"`
+	SeriesOrder int    `yaml:"series_order"`
+
+	Authors     []string    `yaml:"authors"` // ids from WithAuthors
+	Cover       coverMatter `yaml:"cover"`
+	Canonical   string      `yaml:"canonical"`
+	Description string      `yaml:"description"`
+	Lang        string      `yaml:"lang"`
+}
+
+// coverMatter accepts `cover: path.jpg` or `cover: {image: path.jpg, alt: ...}`.
+type coverMatter struct {
+	Image string `yaml:"image"`
+	Alt   string `yaml:"alt"`
+}
+
+func (c *coverMatter) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		return n.Decode(&c.Image)
+	}
+	type plain coverMatter
+	return n.Decode((*plain)(c))
+}
+
+var bom = []byte("\xef\xbb\xbf")
+
+// parseFrontMatter decodes the front matter block at the start of b, if
+// any, and returns it along with the remaining body. Files without front
+// matter yield a zero frontMatter and the whole (normalized) file.
+func parseFrontMatter(b []byte) (frontMatter, []byte, error) {
+	var fm frontMatter
+	b = bytes.TrimPrefix(b, bom)
+	b = bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n"))
+
+	switch {
+	case isJSONObject(b):
+		m, body, err := splitJSON(b)
+		if err != nil {
+			return fm, nil, err
+		}
+		return fm, body, decodeMap(m, &fm)
+	case firstLine(b) == "+++":
+		raw, body, ok := splitDelimited(b, "+++")
+		if !ok {
+			return fm, b, nil
+		}
+		var m map[string]any
+		if err := toml.Unmarshal(raw, &m); err != nil {
+			return fm, nil, err
+		}
+		return fm, body, decodeMap(m, &fm)
+	case firstLine(b) == "---":
+		raw, body, ok := splitDelimited(b, "---")
+		if !ok {
+			return fm, b, nil
+		}
+		return fm, body, yaml.Unmarshal(raw, &fm)
+	}
+	return fm, b, nil
+}
+
+// isJSONObject reports whether b opens with a JSON object, as opposed to
+// body text that merely starts with a brace (e.g. a "{{<" shortcode).
+func isJSONObject(b []byte) bool {
+	if !bytes.HasPrefix(b, []byte("{")) {
+		return false
+	}
+	rest := bytes.TrimLeft(b[1:], " \t\n")
+	return bytes.HasPrefix(rest, []byte(`"`)) || bytes.HasPrefix(rest, []byte("}"))
+}
+
+// firstLine returns the first line of b with surrounding space trimmed.
+func firstLine(b []byte) string {
+	line, _, _ := bytes.Cut(b, []byte("\n"))
+	return string(bytes.TrimSpace(line))
+}
+
+// splitDelimited splits a block opened by a delim line and closed by the
+// next delim line. ok is false when the block is never closed.
+func splitDelimited(b []byte, delim string) (raw, body []byte, ok bool) {
+	_, rest, _ := bytes.Cut(b, []byte("\n"))
+	for off := 0; off < len(rest); {
+		line, _, _ := bytes.Cut(rest[off:], []byte("\n"))
+		next := off + len(line) + 1
+		if string(bytes.TrimSpace(line)) == delim {
+			if next > len(rest) {
+				next = len(rest)
+			}
+			return rest[:off], rest[next:], true
+		}
+		off = next
+	}
+	return nil, b, false
+}
+
+// splitJSON decodes the JSON object at the start of b and returns the
+// text that follows it.
+func splitJSON(b []byte) (map[string]any, []byte, error) {
+	var m map[string]any
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if err := dec.Decode(&m); err != nil {
+		return nil, nil, err
+	}
+	body := b[dec.InputOffset():]
+	// Drop the rest of the closing line.
+	if i := bytes.IndexByte(body, '\n'); i >= 0 && len(bytes.TrimSpace(body[:i])) == 0 {
+		body = body[i+1:]
+	}
+	return m, body, nil
+}
+
+// decodeMap fills fm from generically decoded TOML or JSON by way of YAML,
+// so every format goes through the same field names and custom decoders.
+func decodeMap(m map[string]any, fm *frontMatter) error {
+	out, err := yaml.Marshal(normalizeValue(m))
+	if err != nil {
+		return fmt.Errorf("re-encode: %w", err)
+	}
+	return yaml.Unmarshal(out, fm)
+}
+
+// normalizeValue turns TOML date and time values into the strings
+// parseDate understands.
+func normalizeValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, x := range v {
+			v[k] = normalizeValue(x)
+		}
+	case []any:
+		for i, x := range v {
+			v[i] = normalizeValue(x)
+		}
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case toml.LocalDate:
+		return v.String()
+	case toml.LocalDateTime:
+		return v.String()
+	case toml.LocalTime:
+		return v.String()
+	}
+	return v
+}
@@ -0,0 +1,119 @@
+package blog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFilesStore_FrontMatterFormats(t *testing.T) {
+	td := t.TempDir()
+	write(t, td, "toml.md", `+++
+title = "From Hugo"
+date = 2025-08-01T09:30:00Z
+lastmod = 2025-08-03
+tags = ["go", "hugo"]
+series = "Imports"
+series_order = 2
+
+[cover]
+image = "https://example.com/c.jpg"
+alt = "A cover"
++++
+TOML body.`)
+	write(t, td, "local.md", `+++
+title = "Local"
+date = 2025-07-01T08:00:00
++++
+x`)
+	write(t, td, "json.md", `{
+  "title": "From JSON",
+  "date": "2025-07-15",
+  "tags": ["json"],
+  "series": "Imports",
+  "series_order": 1
+}
+JSON body.`)
+	write(t, td, "crlf.md", "\xef\xbb\xbf---\r\ntitle: \"Windows\"\r\ndate: 2025-06-01\r\n---\r\nLine one.\r\n\r\nLine two.\r\n")
+
+	s, err := NewFilesStore(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, ok := s.BySlug("from-hugo")
+	if !ok {
+		t.Fatalf("toml post missing; have %v", slugs(s.All()))
+	}
+	if want := time.Date(2025, 8, 1, 9, 30, 0, 0, time.UTC); !p.Date.Equal(want) {
+		t.Errorf("toml date=%v, want %v", p.Date, want)
+	}
+	if p.Updated.Format("2006-01-02") != "2025-08-03" {
+		t.Errorf("lastmod not used as updated: %v", p.Updated)
+	}
+	if strings.Join(p.Tags, ",") != "go,hugo" {
+		t.Errorf("toml tags=%v", p.Tags)
+	}
+	if p.Cover == nil || p.Cover.Alt != "A cover" {
+		t.Errorf("toml cover=%+v", p.Cover)
+	}
+	if !strings.Contains(string(p.HTML), "TOML body.") || strings.Contains(string(p.HTML), "+++") {
+		t.Errorf("toml html=%q", p.HTML)
+	}
+	if p.Series == nil || p.Series.Index != 2 {
+		t.Errorf("toml series=%+v", p.Series)
+	}
+
+	if p, ok := s.BySlug("local"); !ok || p.Date.Format("2006-01-02 15:04") != "2025-07-01 08:00" {
+		t.Errorf("toml local date-time: ok=%v date=%v", ok, p.Date)
+	}
+
+	p, ok = s.BySlug("from-json")
+	if !ok {
+		t.Fatalf("json post missing; have %v", slugs(s.All()))
+	}
+	if p.Date.Format("2006-01-02") != "2025-07-15" || strings.Join(p.Tags, ",") != "json" {
+		t.Errorf("json post=%+v", p)
+	}
+	if strings.Contains(string(p.HTML), "{") || !strings.Contains(string(p.HTML), "JSON body.") {
+		t.Errorf("json html=%q", p.HTML)
+	}
+	if p.Series == nil || p.Series.Index != 1 {
+		t.Errorf("json series=%+v", p.Series)
+	}
+
+	p, ok = s.BySlug("windows")
+	if !ok {
+		t.Fatalf("crlf post missing; have %v", slugs(s.All()))
+	}
+	if strings.Contains(string(p.HTML), "\r") || !strings.Contains(string(p.HTML), "<p>Line two.</p>") {
+		t.Errorf("crlf html=%q", p.HTML)
+	}
+}
+
+func TestParseFrontMatter_BodyStartingWithBrace(t *testing.T) {
+	fm, body, err := parseFrontMatter([]byte("{{< note >}}hi{{< /note >}}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm.Title != "" || !strings.HasPrefix(string(body), "{{<") {
+		t.Fatalf("fm=%+v body=%q", fm, body)
+	}
+}
+
+func TestFilesStore_InvalidTOMLNamesFile(t *testing.T) {
+	td := t.TempDir()
+	write(t, td, "bad.md", "+++\ntitle = \"unterminated\n+++\nx")
+	_, err := NewFilesStore(td)
+	if err == nil || !strings.Contains(err.Error(), "front matter bad.md") {
+		t.Fatalf("err=%v, want front matter bad.md", err)
+	}
+}
+
+func slugs(ps []Post) []string {
+	out := make([]string, len(ps))
+	for i, p := range ps {
+		out[i] = p.Slug
+	}
+	return out
+}
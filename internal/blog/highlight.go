@@ -0,0 +1,211 @@
+// internal/blog/highlight.go
+package blog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+/*
+Server-side syntax highlighting for fenced code blocks.
+
+Code is tokenized with chroma and emitted with CSS classes (never inline
+styles) so colors come from a stylesheet that follows the site theme; see
+HighlightCSS. The fence info string selects the language and options:
+
+	```go              highlight as Go
+	```go {3-5,8}      also mark lines 3-5 and 8
+	```go linenos      show line numbers
+	```go {2} linenos  both
+*/
+
+// Highlighting is a goldmark extension that highlights fenced code blocks.
+var Highlighting goldmark.Extender = highlighting{}
+
+type highlighting struct{}
+
+func (highlighting) Extend(m goldmark.Markdown) {
+	// Lower value = higher priority; runs before the default code renderer.
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(codeRenderer{}, 100)))
+}
+
+type codeRenderer struct{}
+
+func (codeRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, renderFencedCode)
+}
+
+func renderFencedCode(w util.BufWriter, src []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	fb := n.(*ast.FencedCodeBlock)
+
+	var info string
+	if fb.Info != nil {
+		info = string(fb.Info.Segment.Value(src))
+	}
+	fi := parseFenceInfo(info)
+
+	var code bytes.Buffer
+	lines := fb.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		seg := lines.At(i)
+		code.Write(seg.Value(src))
+	}
+
+	if err := highlightCode(w, code.String(), fi); err != nil {
+		return ast.WalkStop, err
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+// fenceInfo is the parsed info string of a fenced code block.
+type fenceInfo struct {
+	lang    string
+	lines   [][2]int // highlighted line ranges, 1-based inclusive
+	linenos bool
+}
+
+func parseFenceInfo(info string) fenceInfo {
+	var fi fenceInfo
+	fields := strings.Fields(info)
+	for i, f := range fields {
+		switch {
+		case strings.HasPrefix(f, "{") && strings.HasSuffix(f, "}"):
+			fi.lines = append(fi.lines, parseLineRanges(strings.Trim(f, "{}"))...)
+		case f == "linenos":
+			fi.linenos = true
+		case i == 0:
+			fi.lang = strings.ToLower(f)
+		}
+	}
+	return fi
+}
+
+// parseLineRanges parses "3-5,8" into [[3 5] [8 8]]; malformed parts are skipped.
+func parseLineRanges(s string) [][2]int {
+	var out [][2]int
+	for _, part := range strings.Split(s, ",") {
+		lo, hi, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		a, err := strconv.Atoi(lo)
+		if err != nil || a < 1 {
+			continue
+		}
+		b := a
+		if isRange {
+			if b, err = strconv.Atoi(hi); err != nil || b < a {
+				continue
+			}
+		}
+		out = append(out, [2]int{a, b})
+	}
+	return out
+}
+
+func highlightCode(w io.Writer, code string, fi fenceInfo) error {
+	lexer := lexers.Get(fi.lang)
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	lexer = chroma.Coalesce(lexer)
+
+	it, err := lexer.Tokenise(nil, code)
+	if err != nil {
+		return fmt.Errorf("highlight %s: %w", fi.lang, err)
+	}
+	f := chromahtml.New(
+		chromahtml.WithClasses(true),
+		chromahtml.WithLineNumbers(fi.linenos),
+		chromahtml.HighlightLines(fi.lines),
+	)
+	// The style only matters for inline styles; classes are styled by HighlightCSS.
+	return f.Format(w, styles.Fallback, it)
+}
+
+/************ stylesheet ************/
+
+// DefaultLightStyle and DefaultDarkStyle are the chroma palettes used when
+// none are configured.
+const (
+	DefaultLightStyle = "github"
+	DefaultDarkStyle  = "github-dark"
+)
+
+// HighlightCSS writes the stylesheet for highlighted code. Each palette is
+// scoped to its theme so rules never leak between them: html[data-theme=…]
+// set by theme.js, or the system preference before a theme is chosen
+// (mirroring 00-tokens.css). Unknown style names are an error.
+func HighlightCSS(w io.Writer, light, dark string) error {
+	ls, err := lookupStyle(light, DefaultLightStyle)
+	if err != nil {
+		return err
+	}
+	ds, err := lookupStyle(dark, DefaultDarkStyle)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "/* Syntax highlighting: light=%s dark=%s (generated) */\n", ls.Name, ds.Name)
+	for _, t := range []struct {
+		name  string
+		style *chroma.Style
+	}{{"light", ls}, {"dark", ds}} {
+		var css bytes.Buffer
+		if err := chromahtml.New(chromahtml.WithClasses(true)).WriteCSS(&css, t.style); err != nil {
+			return err
+		}
+		fmt.Fprintf(bw, "\n%s", scopeCSS(css.String(), `html[data-theme="`+t.name+`"] `))
+		fmt.Fprintf(bw, "@media (prefers-color-scheme: %s){\n%s}\n", t.name, scopeCSS(css.String(), "html:not([data-theme]) "))
+	}
+	return bw.Flush()
+}
+
+func lookupStyle(name, def string) (*chroma.Style, error) {
+	if name == "" {
+		name = def
+	}
+	s, ok := styles.Registry[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown highlight style %q", name)
+	}
+	return s, nil
+}
+
+// scopeCSS prefixes every selector of chroma's one-rule-per-line CSS.
+func scopeCSS(css, scope string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(css, "\n") {
+		// Optional leading comment, e.g. "/* Keyword */ .chroma .k { ... }".
+		lead := ""
+		if strings.HasPrefix(line, "/*") {
+			if end := strings.Index(line, "*/"); end >= 0 {
+				lead, line = line[:end+2]+" ", strings.TrimSpace(line[end+2:])
+			}
+		}
+		sel, rest, ok := strings.Cut(line, "{")
+		if !ok {
+			continue
+		}
+		parts := strings.Split(sel, ",")
+		for i, p := range parts {
+			parts[i] = scope + strings.TrimSpace(p)
+		}
+		b.WriteString(lead + strings.Join(parts, ", ") + " {" + rest + "\n")
+	}
+	return b.String()
+}
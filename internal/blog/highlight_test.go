@@ -0,0 +1,67 @@
+package blog
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFenceInfo(t *testing.T) {
+	cases := []struct {
+		in   string
+		want fenceInfo
+	}{
+		{"", fenceInfo{}},
+		{"Go", fenceInfo{lang: "go"}},
+		{"go {3-5,8}", fenceInfo{lang: "go", lines: [][2]int{{3, 5}, {8, 8}}}},
+		{"sh {2} linenos", fenceInfo{lang: "sh", lines: [][2]int{{2, 2}}, linenos: true}},
+		{"go {x,5-2,0,4}", fenceInfo{lang: "go", lines: [][2]int{{4, 4}}}},
+	}
+	for _, c := range cases {
+		if got := parseFenceInfo(c.in); !reflect.DeepEqual(got, c.want) {
+			t.Fatalf("parseFenceInfo(%q) = %+v, want %+v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestHighlighting_RendersClasses(t *testing.T) {
+	src := "```go {2}\npackage main\nfunc main() {}\n```\n"
+	var out bytes.Buffer
+	if err := defaultMarkdown().Convert([]byte(src), &out); err != nil {
+		t.Fatal(err)
+	}
+	html := out.String()
+	if !strings.Contains(html, `class="chroma"`) || !strings.Contains(html, `<span class="kn">package</span>`) {
+		t.Fatalf("code not highlighted with classes: %s", html)
+	}
+	if !strings.Contains(html, `<span class="line hl">`) {
+		t.Fatalf("line 2 not highlighted: %s", html)
+	}
+	if strings.Contains(html, "style=") {
+		t.Fatalf("inline styles emitted: %s", html)
+	}
+}
+
+func TestHighlightCSS(t *testing.T) {
+	var out bytes.Buffer
+	if err := HighlightCSS(&out, "", "monokai"); err != nil {
+		t.Fatal(err)
+	}
+	css := out.String()
+	for _, want := range []string{
+		`html[data-theme="light"] .chroma .k {`,
+		`html[data-theme="dark"] .chroma .k {`,
+		"@media (prefers-color-scheme: dark){\n",
+		"html:not([data-theme]) .chroma {",
+		"dark=monokai",
+	} {
+		if !strings.Contains(css, want) {
+			t.Fatalf("css missing %q", want)
+		}
+	}
+
+	if err := HighlightCSS(&out, "no-such-style", ""); err == nil {
+		t.Fatalf("unknown style: want error")
+	}
+}
@@ -0,0 +1,92 @@
+// internal/blog/markdown.go
+package blog
+
+import (
+	"fmt"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+/*
+Configurable Markdown pipeline.
+
+Sites pick goldmark extensions by name and set renderer options; FilesStore
+takes the resulting goldmark.Markdown via WithMarkdown so tests can inject
+their own.
+*/
+
+// MarkdownOptions configures NewMarkdown.
+type MarkdownOptions struct {
+	Extensions []string // names from Extensions; nil means DefaultExtensions
+	Unsafe     bool     // render raw HTML and unsafe links instead of omitting them
+	HardWraps  bool     // render soft line breaks as <br>
+}
+
+// Extensions maps the names accepted in MarkdownOptions to goldmark extensions.
+var Extensions = map[string]goldmark.Extender{
+	"table":          extension.Table,
+	"strikethrough":  extension.Strikethrough,
+	"tasklist":       extension.TaskList,
+	"linkify":        extension.Linkify,
+	"footnote":       extension.Footnote,
+	"definitionlist": extension.DefinitionList,
+	"typographer":    extension.Typographer,
+	"highlight":      Highlighting,
+	"anchors":        HeadingAnchors,
+	"math":           Math,
+	"diagrams":       Diagrams,
+	"wikilinks":      WikiLinks,
+}
+
+// DefaultExtensions is GitHub-flavored Markdown plus footnotes, definition
+// lists, typographic punctuation, syntax highlighting, heading anchors, math,
+// diagram blocks and wiki links between posts.
+var DefaultExtensions = []string{
+	"table", "strikethrough", "tasklist", "linkify",
+	"footnote", "definitionlist", "typographer", "highlight", "anchors",
+	"math", "diagrams", "wikilinks",
+}
+
+// NewMarkdown builds a goldmark pipeline from o. Unknown extension names are
+// an error so a config typo doesn't silently change rendering. Headings
+// always get stable ids, which the table of contents links to.
+func NewMarkdown(o MarkdownOptions) (goldmark.Markdown, error) {
+	names := o.Extensions
+	if names == nil {
+		names = DefaultExtensions
+	}
+	exts := make([]goldmark.Extender, 0, len(names))
+	for _, n := range names {
+		ext, ok := Extensions[n]
+		if !ok {
+			return nil, fmt.Errorf("markdown: unknown extension %q", n)
+		}
+		exts = append(exts, ext)
+	}
+
+	var hopts []renderer.Option
+	if o.Unsafe {
+		hopts = append(hopts, html.WithUnsafe())
+	}
+	if o.HardWraps {
+		hopts = append(hopts, html.WithHardWraps())
+	}
+	return goldmark.New(
+		goldmark.WithExtensions(exts...),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+		goldmark.WithRendererOptions(hopts...),
+	), nil
+}
+
+// defaultMarkdown is used when no WithMarkdown option is given.
+func defaultMarkdown() goldmark.Markdown {
+	m, err := NewMarkdown(MarkdownOptions{})
+	if err != nil {
+		panic(err) // DefaultExtensions are all registered
+	}
+	return m
+}
@@ -0,0 +1,86 @@
+package blog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func render(t *testing.T, o MarkdownOptions, src string) string {
+	t.Helper()
+	m, err := NewMarkdown(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := m.Convert([]byte(src), &out); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func TestNewMarkdown_DefaultExtensions(t *testing.T) {
+	src := `| a | b |
+|---|---|
+| 1 | 2 |
+
+~~gone~~ and https://example.com "quoted"
+
+- [x] done
+
+Term
+: Definition
+
+Note[^1]
+
+[^1]: Footnote.
+`
+	html := render(t, MarkdownOptions{}, src)
+	for _, want := range []string{
+		"<table>",
+		"<del>gone</del>",
+		`<a href="https://example.com">https://example.com</a>`,
+		"&ldquo;quoted&rdquo;",
+		`<input checked="" disabled="" type="checkbox">`,
+		"<dl>",
+		`class="footnotes"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("missing %q in:\n%s", want, html)
+		}
+	}
+}
+
+func TestNewMarkdown_Options(t *testing.T) {
+	raw := "<b>hi</b>\n"
+	if html := render(t, MarkdownOptions{}, raw); strings.Contains(html, "<b>") {
+		t.Fatalf("raw HTML rendered without Unsafe: %s", html)
+	}
+	if html := render(t, MarkdownOptions{Unsafe: true}, raw); !strings.Contains(html, "<b>hi</b>") {
+		t.Fatalf("raw HTML dropped with Unsafe: %s", html)
+	}
+	if html := render(t, MarkdownOptions{Extensions: []string{}}, "~~x~~"); strings.Contains(html, "<del>") {
+		t.Fatalf("empty extension list still enabled strikethrough: %s", html)
+	}
+	if _, err := NewMarkdown(MarkdownOptions{Extensions: []string{"tables"}}); err == nil {
+		t.Fatalf("unknown extension: want error")
+	}
+}
+
+func TestFilesStore_WithMarkdown(t *testing.T) {
+	td := t.TempDir()
+	write(t, td, "a.md", "---\ntitle: A\n---\n<em>raw</em>\n")
+
+	m, err := NewMarkdown(MarkdownOptions{Unsafe: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewFilesStore(td, WithMarkdown(m))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, _ := s.BySlug("a")
+	if !strings.Contains(string(p.HTML), "<em>raw</em>") {
+		t.Fatalf("injected renderer not used: %s", p.HTML)
+	}
+}
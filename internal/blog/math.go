@@ -0,0 +1,252 @@
+// internal/blog/math.go
+package blog
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+/*
+TeX math in Markdown, rendered server-side to MathML (see mathml.go).
+
+	$e^{i\pi} + 1 = 0$        inline
+	$$\sum_{k=1}^n k$$        display, inline or on its own line
+	$$                        display block
+	\int_0^1 x\,dx
+	$$
+	```math                   display block, as on GitHub
+	\int_0^1 x\,dx
+	```
+
+Inline math follows Pandoc's rules so prices don't turn into math: the
+opening $ must not be followed by a space, and the closing $ must not be
+preceded by a space or followed by a digit. A literal dollar is \$.
+*/
+
+// Math is a goldmark extension that renders TeX math as MathML.
+var Math goldmark.Extender = mathExtension{}
+
+type mathExtension struct{}
+
+func (mathExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		// Ahead of paragraphs and fenced code (700); math lines start with "$$".
+		parser.WithBlockParsers(util.Prioritized(mathBlockParser{}, 650)),
+		parser.WithInlineParsers(util.Prioritized(mathInlineParser{}, 150)),
+		parser.WithASTTransformers(util.Prioritized(mathFenceTransformer{}, 100)),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(mathRenderer{}, 100)))
+}
+
+var (
+	kindMath      = ast.NewNodeKind("Math")
+	kindMathBlock = ast.NewNodeKind("MathBlock")
+)
+
+// mathInline is $...$ (or $$...$$ within a paragraph).
+type mathInline struct {
+	ast.BaseInline
+	tex     string
+	display bool
+}
+
+func (n *mathInline) Kind() ast.NodeKind { return kindMath }
+
+func (n *mathInline) Dump(src []byte, level int) {
+	ast.DumpHelper(n, src, level, map[string]string{"TeX": n.tex}, nil)
+}
+
+// mathBlock is display math on lines of its own; the TeX is in Lines.
+type mathBlock struct {
+	ast.BaseBlock
+	closed bool // the closing "$$" has been seen
+}
+
+func (n *mathBlock) Kind() ast.NodeKind { return kindMathBlock }
+func (n *mathBlock) IsRaw() bool        { return true }
+
+func (n *mathBlock) Dump(src []byte, level int) { ast.DumpHelper(n, src, level, nil, nil) }
+
+/************ parsing ************/
+
+type mathInlineParser struct{}
+
+func (mathInlineParser) Trigger() []byte { return []byte{'$'} }
+
+func (mathInlineParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	display := bytes.HasPrefix(line, []byte("$$"))
+	open := 1
+	if display {
+		open = 2
+	}
+	if len(line) <= open || isSpaceByte(line[open]) {
+		return nil
+	}
+	tex, n, ok := scanMath(line[open:], display)
+	if !ok {
+		return nil
+	}
+	block.Advance(open + n)
+	return &mathInline{tex: string(tex), display: display}
+}
+
+// scanMath finds the closing delimiter in b, which follows an opening one.
+// It returns the TeX and the number of bytes consumed, delimiter included.
+func scanMath(b []byte, display bool) (tex []byte, n int, ok bool) {
+	for i := 0; i < len(b); i++ {
+		switch b[i] {
+		case '\\':
+			i++
+		case '\n':
+			return nil, 0, false
+		case '$':
+			if display {
+				if i+1 < len(b) && b[i+1] == '$' && i > 0 {
+					return b[:i], i + 2, true
+				}
+				return nil, 0, false
+			}
+			if i == 0 || isSpaceByte(b[i-1]) || i+1 < len(b) && b[i+1] >= '0' && b[i+1] <= '9' {
+				return nil, 0, false
+			}
+			return b[:i], i + 1, true
+		}
+	}
+	return nil, 0, false
+}
+
+func isSpaceByte(c byte) bool { return c == ' ' || c == '\t' || c == '\n' || c == '\r' }
+
+var mathFence = []byte("$$")
+
+// mathBlockParser handles "$$" on a line of its own through the next line
+// ending in "$$", and one-line "$$...$$" paragraphs.
+type mathBlockParser struct{}
+
+func (mathBlockParser) Trigger() []byte { return []byte{'$'} }
+
+func (mathBlockParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, seg := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 || !bytes.HasPrefix(line[pos:], mathFence) {
+		return nil, parser.NoChildren
+	}
+	rest := bytes.TrimSpace(line[pos+2:])
+	if len(rest) > 0 {
+		// Only "$$...$$" filling the line; anything else is inline.
+		inner, ok := bytes.CutSuffix(rest, mathFence)
+		if !ok || len(bytes.TrimSpace(inner)) == 0 || bytes.Contains(inner, mathFence) {
+			return nil, parser.NoChildren
+		}
+	}
+	node := &mathBlock{}
+	reader.AdvanceToEOL()
+	appendMathLine(node, seg.WithStart(seg.Start+pos+2), reader.Source())
+	return node, parser.NoChildren
+}
+
+func (mathBlockParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	n := node.(*mathBlock)
+	if n.closed {
+		return parser.Close
+	}
+	_, seg := reader.PeekLine()
+	reader.AdvanceToEOL()
+	if appendMathLine(n, seg, reader.Source()) {
+		return parser.Close
+	}
+	return parser.Continue | parser.NoChildren
+}
+
+// appendMathLine adds seg to the block, minus a closing "$$", and reports
+// whether it closed the block.
+func appendMathLine(node *mathBlock, seg text.Segment, src []byte) bool {
+	v := seg.Value(src)
+	trimmed := bytes.TrimRight(v, " \t\r\n")
+	closed := bytes.HasSuffix(trimmed, mathFence)
+	if closed {
+		seg = seg.WithStop(seg.Start + len(trimmed) - len(mathFence))
+	}
+	if !util.IsBlank(seg.Value(src)) {
+		node.Lines().Append(seg)
+	}
+	node.closed = closed
+	return closed
+}
+
+func (mathBlockParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+func (mathBlockParser) CanInterruptParagraph() bool { return true }
+func (mathBlockParser) CanAcceptIndentedLine() bool { return false }
+
+// mathFenceTransformer turns ```math code blocks into display math.
+type mathFenceTransformer struct{}
+
+func (mathFenceTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	src := reader.Source()
+	replaceFences(doc, func(fb *ast.FencedCodeBlock) ast.Node {
+		if strings.ToLower(string(fb.Language(src))) != "math" {
+			return nil
+		}
+		n := &mathBlock{}
+		n.SetLines(fb.Lines())
+		return n
+	})
+}
+
+// replaceFences swaps each fenced code block for repl's result, if non-nil.
+func replaceFences(doc *ast.Document, repl func(*ast.FencedCodeBlock) ast.Node) {
+	var found []*ast.FencedCodeBlock
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if fb, ok := n.(*ast.FencedCodeBlock); ok && entering {
+			found = append(found, fb)
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, fb := range found {
+		if n := repl(fb); n != nil {
+			fb.Parent().ReplaceChild(fb.Parent(), fb, n)
+		}
+	}
+}
+
+/************ rendering ************/
+
+type mathRenderer struct{}
+
+func (mathRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindMath, renderMath)
+	reg.Register(kindMathBlock, renderMathBlock)
+}
+
+func renderMath(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		n := node.(*mathInline)
+		_, _ = w.WriteString(texToMathML(n.tex, n.display))
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+func renderMathBlock(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	var tex bytes.Buffer
+	lines := node.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		seg := lines.At(i)
+		tex.Write(seg.Value(src))
+	}
+	_, _ = w.WriteString(texToMathML(tex.String(), true))
+	_ = w.WriteByte('\n')
+	return ast.WalkSkipChildren, nil
+}
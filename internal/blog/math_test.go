@@ -0,0 +1,54 @@
+package blog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMath_Inline(t *testing.T) {
+	html := render(t, MarkdownOptions{}, "Euler: $e^{i\\pi}+1=0$, and $$x$$ too.\n")
+	if !strings.Contains(html, "<p>Euler: <math><semantics>") {
+		t.Fatalf("inline math not rendered: %s", html)
+	}
+	if !strings.Contains(html, `too.</p>`) || !strings.Contains(html, `<math display="block">`) {
+		t.Fatalf("inline $$ not display math: %s", html)
+	}
+}
+
+func TestMath_DollarsThatArentMath(t *testing.T) {
+	for _, src := range []string{
+		"It costs $5 and $10 later.",
+		"From $ 5 to $ 10.",
+		"Between $5$10.",
+		"A lone \\$x\\$ escaped.",
+		"`$x$` in code.",
+	} {
+		html := render(t, MarkdownOptions{}, src+"\n")
+		if strings.Contains(html, "<math") {
+			t.Errorf("%q rendered math: %s", src, html)
+		}
+	}
+}
+
+func TestMath_Blocks(t *testing.T) {
+	src := "Intro\n$$\n\\int_0^1 x\\,dx\n$$\n\n$$ a^2 $$\n\n```math\nb_1\n```\n"
+	html := render(t, MarkdownOptions{}, src)
+	if n := strings.Count(html, `<math display="block">`); n != 3 {
+		t.Fatalf("display blocks=%d, want 3:\n%s", n, html)
+	}
+	if !strings.Contains(html, "<p>Intro</p>") {
+		t.Fatalf("$$ did not interrupt the paragraph:\n%s", html)
+	}
+	for _, want := range []string{"<mo>∫</mo>", "<msup><mi>a</mi><mn>2</mn></msup>", "<msub><mi>b</mi><mn>1</mn></msub>"} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("missing %q in:\n%s", want, html)
+		}
+	}
+}
+
+func TestMath_Disabled(t *testing.T) {
+	html := render(t, MarkdownOptions{Extensions: []string{"highlight"}}, "$x$\n\n```math\nx\n```\n")
+	if strings.Contains(html, "<math") {
+		t.Fatalf("math rendered without the extension: %s", html)
+	}
+}
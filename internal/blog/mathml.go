@@ -0,0 +1,717 @@
+// internal/blog/mathml.go
+package blog
+
+import (
+	"html"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+/*
+TeX math to MathML.
+
+texToMathML converts the subset of TeX that turns up in posts: scripts,
+fractions, roots, Greek letters and common symbols, big operators with
+limits, \left/\right fences, accents, font commands (\mathbf, \mathbb,
+\mathcal, \mathrm), \text and the matrix, cases and aligned environments.
+The output targets MathML Core, which browsers render natively, so math
+needs no script or web font.
+
+Conversion never fails: anything unrecognised is shown as an <merror>
+holding the original TeX, so a typo is visible on the page instead of
+silently dropped. The source is kept as an annotation for copy and paste.
+*/
+
+// texToMathML renders tex as a <math> element; display selects block layout.
+func texToMathML(tex string, display bool) string {
+	p := &texParser{src: []rune(tex)}
+	body := p.row()
+
+	var b strings.Builder
+	b.WriteString("<math")
+	if display {
+		b.WriteString(` display="block"`)
+	}
+	b.WriteString("><semantics><mrow>")
+	b.WriteString(body)
+	b.WriteString(`</mrow><annotation encoding="application/x-tex">`)
+	b.WriteString(html.EscapeString(strings.TrimSpace(tex)))
+	b.WriteString("</annotation></semantics></math>")
+	return b.String()
+}
+
+type texParser struct {
+	src  []rune
+	pos  int
+	font string // texFonts value while inside \mathbf{...} and friends
+}
+
+// texAtom is one parsed element before any sub- or superscripts.
+type texAtom struct {
+	ml     string
+	limits bool // scripts go under and over (\sum, \lim, ...)
+	fn     bool // a function name like \sin, spaced from its argument
+}
+
+/************ tokens ************/
+
+// next consumes and returns the next token: a control word ("\frac"), a
+// control symbol ("\,"), or a single character. Spaces are skipped, as in
+// TeX math mode. It returns "" at the end of input.
+func (p *texParser) next() string {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return ""
+	}
+	r := p.src[p.pos]
+	p.pos++
+	if r != '\\' {
+		return string(r)
+	}
+	if p.pos >= len(p.src) {
+		return `\`
+	}
+	start := p.pos
+	for p.pos < len(p.src) && isASCIILetter(p.src[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		p.pos++
+	}
+	return `\` + string(p.src[start:p.pos])
+}
+
+func (p *texParser) peek() string {
+	save := p.pos
+	t := p.next()
+	p.pos = save
+	return t
+}
+
+func (p *texParser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+// rawGroup reads a {...} argument verbatim. Without a brace it takes the
+// next token instead.
+func (p *texParser) rawGroup() string {
+	p.skipSpace()
+	if p.pos >= len(p.src) || p.src[p.pos] != '{' {
+		return p.next()
+	}
+	depth := 0
+	start := p.pos + 1
+	for i := p.pos; i < len(p.src); i++ {
+		switch p.src[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				p.pos = i + 1
+				return string(p.src[start:i])
+			}
+		}
+	}
+	p.pos = len(p.src)
+	return string(p.src[start:])
+}
+
+func isASCIILetter(r rune) bool { return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' }
+
+/************ structure ************/
+
+// row parses elements until one of the stop tokens (left unconsumed) or
+// the end of input.
+func (p *texParser) row(stop ...string) string {
+	var b strings.Builder
+	for {
+		t := p.peek()
+		if t == "" || slices.Contains(stop, t) {
+			return b.String()
+		}
+		b.WriteString(p.item())
+	}
+}
+
+// group parses the rest of a {...} group whose brace was consumed.
+func (p *texParser) group() string {
+	r := p.row("}")
+	p.next()
+	return "<mrow>" + r + "</mrow>"
+}
+
+// arg parses one macro argument: a {group} or a single token.
+func (p *texParser) arg() string {
+	if p.peek() == "{" {
+		p.next()
+		return p.group()
+	}
+	return p.atom(true).ml
+}
+
+// item parses an atom and its scripts.
+func (p *texParser) item() string {
+	a := texAtom{ml: "<mrow></mrow>"}
+	if t := p.peek(); t != "^" && t != "_" && t != "'" {
+		a = p.atom(false)
+	}
+
+	var sub string
+	var sup []string
+	for done := false; !done; {
+		switch p.peek() {
+		case "_":
+			p.next()
+			sub = p.arg()
+		case "^":
+			p.next()
+			sup = append(sup, p.arg())
+		case "'":
+			p.next()
+			sup = append(sup, "<mo>′</mo>")
+		case `\limits`:
+			p.next()
+			a.limits = true
+		case `\nolimits`:
+			p.next()
+			a.limits = false
+		default:
+			done = true
+		}
+	}
+
+	out := a.ml
+	if sub != "" || len(sup) > 0 {
+		sp := strings.Join(sup, "")
+		if len(sup) > 1 {
+			sp = "<mrow>" + sp + "</mrow>"
+		}
+		tags := [3]string{"msub", "msup", "msubsup"}
+		if a.limits {
+			tags = [3]string{"munder", "mover", "munderover"}
+		}
+		switch {
+		case len(sup) == 0:
+			out = wrap(tags[0], "", out+sub)
+		case sub == "":
+			out = wrap(tags[1], "", out+sp)
+		default:
+			out = wrap(tags[2], "", out+sub+sp)
+		}
+	}
+	if a.fn {
+		// TeX puts a thin space between an operator name and an ordinary
+		// argument, but not before a delimiter.
+		switch p.peek() {
+		case "", "(", "[", "|", `\left`, `\{`, `\|`, `\langle`, "}", "&", `\\`, `\right`, `\end`:
+		default:
+			out += `<mspace width="0.1667em"/>`
+		}
+	}
+	return out
+}
+
+// atom parses one element. single limits a number to one digit, as in
+// x^23 (which TeX reads as x^2 followed by 3).
+func (p *texParser) atom(single bool) texAtom {
+	t := p.next()
+	switch {
+	case t == "":
+		return texAtom{ml: "<mrow></mrow>"}
+	case t == "{":
+		return texAtom{ml: p.group()}
+	case t == "}":
+		return texAtom{ml: merror("}")}
+	case t == "&" || t == `\\`:
+		return texAtom{} // outside an environment
+	case strings.HasPrefix(t, `\`) && len(t) > 1:
+		return p.command(t[1:])
+	}
+
+	r := []rune(t)[0]
+	switch {
+	case r >= '0' && r <= '9':
+		num := t
+		for !single && p.pos < len(p.src) {
+			c := p.src[p.pos]
+			if c >= '0' && c <= '9' || c == '.' && p.pos+1 < len(p.src) && unicode.IsDigit(p.src[p.pos+1]) {
+				num += string(c)
+				p.pos++
+				continue
+			}
+			break
+		}
+		return texAtom{ml: "<mn>" + html.EscapeString(mapRunes(num, p.font)) + "</mn>"}
+	case unicode.IsLetter(r):
+		return texAtom{ml: p.ident(r)}
+	case r == '~':
+		return texAtom{ml: `<mspace width="0.3333em"/>`}
+	}
+	return texAtom{ml: mo(texASCIIOps[t], t)}
+}
+
+// ident renders a letter in the current font.
+func (p *texParser) ident(r rune) string {
+	if p.font == "rm" {
+		return `<mi mathvariant="normal">` + html.EscapeString(string(r)) + "</mi>"
+	}
+	return "<mi>" + html.EscapeString(mapRunes(string(r), p.font)) + "</mi>"
+}
+
+/************ commands ************/
+
+func (p *texParser) command(name string) texAtom {
+	if s, ok := texIdentifiers[name]; ok {
+		return texAtom{ml: "<mi>" + s + "</mi>"}
+	}
+	if s, ok := texUpright[name]; ok {
+		return texAtom{ml: `<mi mathvariant="normal">` + s + "</mi>"}
+	}
+	if s, ok := texSymbols[name]; ok {
+		return texAtom{ml: mo(s, s)}
+	}
+	if s, ok := texDelimiters[name]; ok {
+		return texAtom{ml: fixedMo(s)}
+	}
+	if s, ok := texBigOps[name]; ok {
+		return texAtom{ml: `<mo movablelimits="true" form="prefix">` + s + "</mo>", limits: true}
+	}
+	if slices.Contains(texFunctions, name) {
+		return texAtom{ml: "<mi>" + name + "</mi>", fn: true}
+	}
+	if w, ok := texSpaces[name]; ok {
+		if w == "" {
+			return texAtom{}
+		}
+		return texAtom{ml: `<mspace width="` + w + `"/>`}
+	}
+	if f, ok := texFonts[name]; ok {
+		saved := p.font
+		p.font = f
+		ml := p.arg()
+		p.font = saved
+		return texAtom{ml: ml}
+	}
+	if a, ok := texAccents[name]; ok {
+		return p.accent(a)
+	}
+	if size, ok := texBigDelims[name]; ok {
+		d := p.delim()
+		return texAtom{ml: `<mo minsize="` + size + `" maxsize="` + size + `" stretchy="true" symmetric="true">` + html.EscapeString(d) + "</mo>"}
+	}
+
+	switch name {
+	case "frac", "dfrac", "tfrac", "cfrac":
+		ml := wrap("mfrac", "", p.arg()+p.arg())
+		switch name {
+		case "dfrac", "cfrac":
+			ml = wrap("mstyle", ` displaystyle="true"`, ml)
+		case "tfrac":
+			ml = wrap("mstyle", ` displaystyle="false"`, ml)
+		}
+		return texAtom{ml: ml}
+	case "binom":
+		ml := wrap("mfrac", ` linethickness="0"`, p.arg()+p.arg())
+		return texAtom{ml: "<mrow>" + fence("(", "prefix") + ml + fence(")", "postfix") + "</mrow>"}
+	case "sqrt":
+		if p.peek() == "[" {
+			p.next()
+			index := p.row("]")
+			p.next()
+			return texAtom{ml: wrap("mroot", "", p.arg()+"<mrow>"+index+"</mrow>")}
+		}
+		return texAtom{ml: wrap("msqrt", "", p.arg())}
+	case "overset", "stackrel":
+		over := p.arg()
+		return texAtom{ml: wrap("mover", "", p.arg()+over)}
+	case "underset":
+		under := p.arg()
+		return texAtom{ml: wrap("munder", "", p.arg()+under)}
+	case "text", "textrm", "textit", "textbf", "textnormal", "mbox", "mathtext":
+		return texAtom{ml: mtext(p.rawGroup())}
+	case "operatorname":
+		star := p.pos < len(p.src) && p.src[p.pos] == '*'
+		if star {
+			p.pos++
+		}
+		op := html.EscapeString(strings.TrimSpace(p.rawGroup()))
+		if star {
+			return texAtom{ml: `<mo movablelimits="true" form="prefix">` + op + "</mo>", limits: true}
+		}
+		return texAtom{ml: "<mi>" + op + "</mi>", fn: true}
+	case "left":
+		open := p.delim()
+		body := p.row(`\right`)
+		p.next()
+		return texAtom{ml: "<mrow>" + fence(open, "prefix") + body + fence(p.delim(), "postfix") + "</mrow>"}
+	case "middle":
+		return texAtom{ml: `<mo stretchy="true">` + html.EscapeString(p.delim()) + "</mo>"}
+	case "right":
+		p.delim()
+		return texAtom{ml: merror(`\right`)}
+	case "not":
+		t := p.next()
+		if s, ok := texNegated[t]; ok {
+			return texAtom{ml: mo(s, s)}
+		}
+		op := t
+		if s, ok := texSymbols[strings.TrimPrefix(t, `\`)]; ok {
+			op = s
+		}
+		return texAtom{ml: mo(op+"̸", op+"̸")}
+	case "pmod":
+		return texAtom{ml: `<mrow><mspace width="1em"/>` + fixedMo("(") + `<mi>mod</mi><mspace width="0.3333em"/>` + p.arg() + fixedMo(")") + "</mrow>"}
+	case "bmod":
+		return texAtom{ml: "<mo>mod</mo>"}
+	case "begin":
+		return texAtom{ml: p.env(strings.TrimSpace(p.rawGroup()))}
+	case "end":
+		p.rawGroup()
+		return texAtom{ml: merror(`\end`)}
+	case "tag", "label":
+		p.rawGroup()
+		return texAtom{}
+	case "displaystyle", "textstyle", "scriptstyle", "nonumber", "notag":
+		return texAtom{}
+	}
+	if len(name) == 1 && !isASCIILetter(rune(name[0])) {
+		// \{ \} \% \$ \# \& \_ and other escaped characters.
+		if name == "{" || name == "}" {
+			return texAtom{ml: fixedMo(name)}
+		}
+		return texAtom{ml: mo(name, name)}
+	}
+	return texAtom{ml: merror(`\` + name)}
+}
+
+// accent parses the argument of \hat, \overline, \underbrace and friends.
+func (p *texParser) accent(a texAccent) texAtom {
+	base := p.arg()
+	op := `<mo stretchy="` + boolAttr(a.stretchy) + `">` + a.char + "</mo>"
+	if a.under {
+		return texAtom{ml: wrap("munder", ` accentunder="true"`, base+op), limits: a.limits}
+	}
+	return texAtom{ml: wrap("mover", ` accent="true"`, base+op), limits: a.limits}
+}
+
+// delim reads the delimiter after \left, \right, \big and so on; "." means none.
+func (p *texParser) delim() string {
+	t := p.next()
+	if t == "." || t == "" {
+		return ""
+	}
+	if s, ok := texDelimiters[strings.TrimPrefix(t, `\`)]; ok && strings.HasPrefix(t, `\`) {
+		return s
+	}
+	if t == `\{` || t == `\}` {
+		return t[1:]
+	}
+	return t
+}
+
+// env parses the body of \begin{name}...\end{name} into a table.
+func (p *texParser) env(name string) string {
+	if name == "array" {
+		p.rawGroup() // column spec
+	}
+	var rows [][]string
+	var cur []string
+	for {
+		cur = append(cur, p.row("&", `\\`, `\end`))
+		t := p.next()
+		if t == "&" {
+			continue
+		}
+		rows = append(rows, cur)
+		cur = nil
+		if t == `\end` {
+			p.rawGroup()
+		}
+		if t != `\\` {
+			break
+		}
+	}
+	// A trailing \\ leaves an empty last row.
+	if last := rows[len(rows)-1]; len(rows) > 1 && len(last) == 1 && last[0] == "" {
+		rows = rows[:len(rows)-1]
+	}
+
+	var b strings.Builder
+	for _, r := range rows {
+		b.WriteString("<mtr>")
+		for _, c := range r {
+			b.WriteString("<mtd>" + c + "</mtd>")
+		}
+		b.WriteString("</mtr>")
+	}
+	cells := b.String()
+
+	switch name {
+	case "matrix", "array", "smallmatrix":
+		return wrap("mtable", "", cells)
+	case "pmatrix", "bmatrix", "Bmatrix", "vmatrix", "Vmatrix":
+		d := texMatrixFences[name]
+		return "<mrow>" + fence(d[0], "prefix") + wrap("mtable", "", cells) + fence(d[1], "postfix") + "</mrow>"
+	case "cases":
+		return "<mrow>" + fence("{", "prefix") + wrap("mtable", ` class="math-cases"`, cells) + "</mrow>"
+	case "aligned", "align", "align*", "split", "eqnarray", "eqnarray*":
+		return wrap("mtable", ` class="math-aligned" displaystyle="true"`, cells)
+	case "gathered", "gather", "gather*":
+		return wrap("mtable", ` displaystyle="true"`, cells)
+	}
+	return merror(`\begin{`+name+`}`) + wrap("mtable", "", cells)
+}
+
+/************ output helpers ************/
+
+func wrap(tag, attrs, body string) string {
+	return "<" + tag + attrs + ">" + body + "</" + tag + ">"
+}
+
+// mo renders an operator; s is the character(s) to show, falling back to
+// raw when s is empty.
+func mo(s, raw string) string {
+	if s == "" {
+		s = raw
+	}
+	if strings.ContainsAny(s, "()[]{}|/") {
+		return fixedMo(s)
+	}
+	return "<mo>" + html.EscapeString(s) + "</mo>"
+}
+
+// fixedMo renders a delimiter at its natural size. Bare brackets in TeX
+// don't grow with their contents; \left and \right are for that.
+func fixedMo(s string) string {
+	return `<mo stretchy="false">` + html.EscapeString(s) + "</mo>"
+}
+
+func fence(s, form string) string {
+	if s == "" {
+		return ""
+	}
+	return `<mo fence="true" form="` + form + `">` + html.EscapeString(s) + "</mo>"
+}
+
+// mtext renders \text content. Edge spaces are made non-breaking since
+// MathML trims them.
+func mtext(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && !isASCIILetter(rune(s[i+1])) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	t := b.String()
+	trimmed := strings.TrimLeft(t, " ")
+	t = strings.Repeat(" ", len(t)-len(trimmed)) + trimmed
+	trimmed = strings.TrimRight(t, " ")
+	t = trimmed + strings.Repeat(" ", len(t)-len(trimmed))
+	return "<mtext>" + html.EscapeString(t) + "</mtext>"
+}
+
+func merror(tex string) string {
+	return "<merror><mtext>" + html.EscapeString(tex) + "</mtext></merror>"
+}
+
+func boolAttr(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
+
+// mapRunes maps letters and digits to the Unicode mathematical
+// alphanumerics of font ("bold", "bb" or "cal"); other runes are kept.
+func mapRunes(s, font string) string {
+	if font == "" || font == "rm" {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		b.WriteRune(mathAlpha(r, font))
+	}
+	return b.String()
+}
+
+func mathAlpha(r rune, font string) rune {
+	switch font {
+	case "bold":
+		switch {
+		case r >= 'A' && r <= 'Z':
+			return 0x1D400 + r - 'A'
+		case r >= 'a' && r <= 'z':
+			return 0x1D41A + r - 'a'
+		case r >= '0' && r <= '9':
+			return 0x1D7CE + r - '0'
+		}
+	case "bb":
+		if x, ok := doubleStruckExceptions[r]; ok {
+			return x
+		}
+		switch {
+		case r >= 'A' && r <= 'Z':
+			return 0x1D538 + r - 'A'
+		case r >= 'a' && r <= 'z':
+			return 0x1D552 + r - 'a'
+		case r >= '0' && r <= '9':
+			return 0x1D7D8 + r - '0'
+		}
+	case "cal":
+		if x, ok := scriptExceptions[r]; ok {
+			return x
+		}
+		switch {
+		case r >= 'A' && r <= 'Z':
+			return 0x1D49C + r - 'A'
+		case r >= 'a' && r <= 'z':
+			return 0x1D4B6 + r - 'a'
+		}
+	}
+	return r
+}
+
+/************ tables ************/
+
+// Letters in the Letterlike Symbols block, which the math alphanumerics skip.
+var (
+	doubleStruckExceptions = map[rune]rune{'C': 'ℂ', 'H': 'ℍ', 'N': 'ℕ', 'P': 'ℙ', 'Q': 'ℚ', 'R': 'ℝ', 'Z': 'ℤ'}
+	scriptExceptions       = map[rune]rune{
+		'B': 'ℬ', 'E': 'ℰ', 'F': 'ℱ', 'H': 'ℋ', 'I': 'ℐ', 'L': 'ℒ', 'M': 'ℳ', 'R': 'ℛ',
+		'e': 'ℯ', 'g': 'ℊ', 'o': 'ℴ',
+	}
+)
+
+var texFonts = map[string]string{
+	"mathbf": "bold", "boldsymbol": "bold", "bm": "bold", "mathbb": "bb",
+	"mathcal": "cal", "mathscr": "cal", "mathit": "",
+	"mathrm": "rm", "mathup": "rm", "mathsf": "rm", "mathtt": "rm",
+}
+
+var texIdentifiers = map[string]string{
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ϵ",
+	"varepsilon": "ε", "zeta": "ζ", "eta": "η", "theta": "θ", "vartheta": "ϑ",
+	"iota": "ι", "kappa": "κ", "lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ",
+	"pi": "π", "varpi": "ϖ", "rho": "ρ", "varrho": "ϱ", "sigma": "σ",
+	"varsigma": "ς", "tau": "τ", "upsilon": "υ", "phi": "ϕ", "varphi": "φ",
+	"chi": "χ", "psi": "ψ", "omega": "ω",
+	"infty": "∞", "emptyset": "∅", "varnothing": "∅", "ell": "ℓ", "hbar": "ℏ",
+	"partial": "∂", "nabla": "∇", "aleph": "ℵ", "Re": "ℜ", "Im": "ℑ", "wp": "℘",
+}
+
+// Capital Greek is upright in TeX.
+var texUpright = map[string]string{
+	"Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ",
+	"Pi": "Π", "Sigma": "Σ", "Upsilon": "Υ", "Phi": "Φ", "Psi": "Ψ", "Omega": "Ω",
+}
+
+var texSymbols = map[string]string{
+	// binary operators
+	"cdot": "⋅", "times": "×", "div": "÷", "pm": "±", "mp": "∓", "ast": "∗",
+	"star": "⋆", "circ": "∘", "bullet": "∙", "oplus": "⊕", "ominus": "⊖",
+	"otimes": "⊗", "odot": "⊙", "cup": "∪", "cap": "∩", "setminus": "∖",
+	"wedge": "∧", "land": "∧", "vee": "∨", "lor": "∨", "neg": "¬", "lnot": "¬",
+	// relations
+	"le": "≤", "leq": "≤", "ge": "≥", "geq": "≥", "ne": "≠", "neq": "≠",
+	"ll": "≪", "gg": "≫", "approx": "≈", "sim": "∼", "simeq": "≃", "cong": "≅",
+	"equiv": "≡", "propto": "∝", "perp": "⊥", "parallel": "∥", "mid": "∣",
+	"in": "∈", "notin": "∉", "ni": "∋", "subset": "⊂", "subseteq": "⊆",
+	"supset": "⊃", "supseteq": "⊇", "vdash": "⊢", "models": "⊨", "coloneqq": "≔",
+	"prec": "≺", "succ": "≻", "preceq": "⪯", "succeq": "⪰",
+	// arrows
+	"to": "→", "rightarrow": "→", "leftarrow": "←", "gets": "←",
+	"leftrightarrow": "↔", "Rightarrow": "⇒", "Leftarrow": "⇐",
+	"Leftrightarrow": "⇔", "implies": "⟹", "impliedby": "⟸", "iff": "⟺",
+	"mapsto": "↦", "uparrow": "↑", "downarrow": "↓", "longrightarrow": "⟶",
+	"longleftarrow": "⟵", "longmapsto": "⟼", "hookrightarrow": "↪",
+	// logic and misc
+	"forall": "∀", "exists": "∃", "nexists": "∄", "therefore": "∴",
+	"because": "∵", "colon": ":", "prime": "′", "angle": "∠",
+	"triangle": "△", "dagger": "†", "top": "⊤", "bot": "⊥",
+	"ldots": "…", "dots": "…", "cdots": "⋯", "vdots": "⋮", "ddots": "⋱",
+	"int": "∫", "iint": "∬", "iiint": "∭", "oint": "∮",
+}
+
+var texDelimiters = map[string]string{
+	"langle": "⟨", "rangle": "⟩", "lfloor": "⌊", "rfloor": "⌋",
+	"lceil": "⌈", "rceil": "⌉", "vert": "|", "lvert": "|", "rvert": "|",
+	"Vert": "‖", "lVert": "‖", "rVert": "‖", "|": "‖", "backslash": "\\",
+	"lbrace": "{", "rbrace": "}",
+}
+
+var texNegated = map[string]string{
+	"=": "≠", "<": "≮", ">": "≯", `\in`: "∉", `\equiv`: "≢", `\subset`: "⊄",
+	`\subseteq`: "⊈", `\le`: "≰", `\leq`: "≰", `\ge`: "≱", `\geq`: "≱",
+	`\sim`: "≁", `\approx`: "≉", `\mid`: "∤", `\parallel`: "∦", `\exists`: "∄",
+}
+
+var texASCIIOps = map[string]string{"-": "−", "*": "∗"}
+
+// Operators whose scripts become limits above and below in display math.
+var texBigOps = map[string]string{
+	"sum": "∑", "prod": "∏", "coprod": "∐", "bigcup": "⋃", "bigcap": "⋂",
+	"bigoplus": "⨁", "bigotimes": "⨂", "bigvee": "⋁", "bigwedge": "⋀",
+	"lim": "lim", "liminf": "lim inf", "limsup": "lim sup", "max": "max",
+	"min": "min", "sup": "sup", "inf": "inf", "det": "det", "gcd": "gcd",
+	"Pr": "Pr", "argmax": "arg max", "argmin": "arg min",
+}
+
+var texFunctions = []string{
+	"sin", "cos", "tan", "cot", "sec", "csc", "arcsin", "arccos", "arctan",
+	"sinh", "cosh", "tanh", "coth", "log", "ln", "lg", "exp", "arg", "deg",
+	"dim", "hom", "ker",
+}
+
+// Widths of TeX's spacing commands; "" means ignored (MathML Core has no
+// negative space).
+var texSpaces = map[string]string{
+	",": "0.1667em", "thinspace": "0.1667em", ":": "0.2222em", ">": "0.2222em",
+	"medspace": "0.2222em", ";": "0.2778em", "thickspace": "0.2778em",
+	" ": "0.3333em", "quad": "1em", "qquad": "2em", "!": "",
+}
+
+type texAccent struct {
+	char     string
+	under    bool
+	stretchy bool
+	limits   bool // \overbrace{x}^{n} puts n above the brace
+}
+
+var texAccents = map[string]texAccent{
+	"hat":            {char: "^"},
+	"widehat":        {char: "^", stretchy: true},
+	"check":          {char: "ˇ"},
+	"tilde":          {char: "~"},
+	"widetilde":      {char: "~", stretchy: true},
+	"bar":            {char: "¯"},
+	"overline":       {char: "‾", stretchy: true},
+	"vec":            {char: "→"},
+	"overrightarrow": {char: "→", stretchy: true},
+	"overleftarrow":  {char: "←", stretchy: true},
+	"dot":            {char: "˙"},
+	"ddot":           {char: "¨"},
+	"acute":          {char: "´"},
+	"grave":          {char: "`"},
+	"breve":          {char: "˘"},
+	"underline":      {char: "_", under: true, stretchy: true},
+	"overbrace":      {char: "⏞", stretchy: true, limits: true},
+	"underbrace":     {char: "⏟", under: true, stretchy: true, limits: true},
+}
+
+var texBigDelims = map[string]string{
+	"big": "1.2em", "bigl": "1.2em", "bigr": "1.2em", "bigm": "1.2em",
+	"Big": "1.623em", "Bigl": "1.623em", "Bigr": "1.623em", "Bigm": "1.623em",
+	"bigg": "2.047em", "biggl": "2.047em", "biggr": "2.047em", "biggm": "2.047em",
+	"Bigg": "2.470em", "Biggl": "2.470em", "Biggr": "2.470em", "Biggm": "2.470em",
+}
+
+var texMatrixFences = map[string][2]string{
+	"pmatrix": {"(", ")"}, "bmatrix": {"[", "]"}, "Bmatrix": {"{", "}"},
+	"vmatrix": {"|", "|"}, "Vmatrix": {"‖", "‖"},
+}
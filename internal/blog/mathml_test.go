@@ -0,0 +1,69 @@
+package blog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTeXToMathML(t *testing.T) {
+	cases := []struct {
+		tex  string
+		want string // expected inside the outer <mrow>
+	}{
+		{`x^2`, `<msup><mi>x</mi><mn>2</mn></msup>`},
+		{`x^23`, `<msup><mi>x</mi><mn>2</mn></msup><mn>3</mn>`},
+		{`a_{ij}`, `<msub><mi>a</mi><mrow><mi>i</mi><mi>j</mi></mrow></msub>`},
+		{`x_1^2`, `<msubsup><mi>x</mi><mn>1</mn><mn>2</mn></msubsup>`},
+		{`f'(x)`, `<msup><mi>f</mi><mo>′</mo></msup><mo stretchy="false">(</mo><mi>x</mi><mo stretchy="false">)</mo>`},
+		{`3.14 - 1`, `<mn>3.14</mn><mo>−</mo><mn>1</mn>`},
+		{`\frac{a}{b}`, `<mfrac><mrow><mi>a</mi></mrow><mrow><mi>b</mi></mrow></mfrac>`},
+		{`\frac12`, `<mfrac><mn>1</mn><mn>2</mn></mfrac>`},
+		{`\sqrt[3]{x}`, `<mroot><mrow><mi>x</mi></mrow><mrow><mn>3</mn></mrow></mroot>`},
+		{`\alpha \le \Omega`, `<mi>α</mi><mo>≤</mo><mi mathvariant="normal">Ω</mi>`},
+		{`\sum_{k=1}^n k`, `<munderover><mo movablelimits="true" form="prefix">∑</mo><mrow><mi>k</mi><mo>=</mo><mn>1</mn></mrow><mi>n</mi></munderover><mi>k</mi>`},
+		{`\int_0^1`, `<msubsup><mo>∫</mo><mn>0</mn><mn>1</mn></msubsup>`},
+		{`\sin x`, `<mi>sin</mi><mspace width="0.1667em"/><mi>x</mi>`},
+		{`\sin(x)`, `<mi>sin</mi><mo stretchy="false">(</mo><mi>x</mi><mo stretchy="false">)</mo>`},
+		{`\left( x \right.`, `<mrow><mo fence="true" form="prefix">(</mo><mi>x</mi></mrow>`},
+		{`\hat{x}`, `<mover accent="true"><mrow><mi>x</mi></mrow><mo stretchy="false">^</mo></mover>`},
+		{`\mathbb{R}^n`, `<msup><mrow><mi>ℝ</mi></mrow><mi>n</mi></msup>`},
+		{`\mathbf{v}`, `<mrow><mi>𝐯</mi></mrow>`},
+		{`\mathrm{d}x`, `<mrow><mi mathvariant="normal">d</mi></mrow><mi>x</mi>`},
+		{`\text{ if } x`, "<mtext>\u00a0if\u00a0</mtext><mi>x</mi>"}, // edge spaces kept
+		{`\not= \{1\}`, `<mo>≠</mo><mo stretchy="false">{</mo><mn>1</mn><mo stretchy="false">}</mo>`},
+		{`a<b`, `<mi>a</mi><mo>&lt;</mo><mi>b</mi>`},
+		{`\begin{pmatrix} a & b \\ c & d \end{pmatrix}`,
+			`<mrow><mo fence="true" form="prefix">(</mo><mtable><mtr><mtd><mi>a</mi></mtd><mtd><mi>b</mi></mtd></mtr><mtr><mtd><mi>c</mi></mtd><mtd><mi>d</mi></mtd></mtr></mtable><mo fence="true" form="postfix">)</mo></mrow>`},
+		{`\begin{aligned} a &= b \\ \end{aligned}`,
+			`<mtable class="math-aligned" displaystyle="true"><mtr><mtd><mi>a</mi></mtd><mtd><mo>=</mo><mi>b</mi></mtd></mtr></mtable>`},
+	}
+	for _, c := range cases {
+		got := texToMathML(c.tex, false)
+		want := "<math><semantics><mrow>" + c.want + "</mrow>"
+		if !strings.HasPrefix(got, want) {
+			t.Errorf("texToMathML(%q)\n got %s\nwant %s…", c.tex, got, want)
+		}
+	}
+}
+
+func TestTeXToMathML_DisplayAndAnnotation(t *testing.T) {
+	got := texToMathML(` a<b `, true)
+	if !strings.HasPrefix(got, `<math display="block">`) {
+		t.Fatalf("not display: %s", got)
+	}
+	if !strings.HasSuffix(got, `<annotation encoding="application/x-tex">a&lt;b</annotation></semantics></math>`) {
+		t.Fatalf("annotation: %s", got)
+	}
+}
+
+func TestTeXToMathML_ErrorsAreVisible(t *testing.T) {
+	for _, tex := range []string{`\foo{x}`, `a}`, `\frac{a`, `\begin{pmatrix} a`, `x^`, `\left(`, `\end{x}`} {
+		got := texToMathML(tex, false)
+		if !strings.HasSuffix(got, "</math>") {
+			t.Errorf("texToMathML(%q) = %s", tex, got)
+		}
+	}
+	if got := texToMathML(`\foo`, false); !strings.Contains(got, `<merror><mtext>\foo</mtext></merror>`) {
+		t.Errorf("unknown command not flagged: %s", got)
+	}
+}
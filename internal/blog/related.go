@@ -0,0 +1,171 @@
+// internal/blog/related.go
+package blog
+
+import (
+	"math"
+	"sort"
+)
+
+/*
+Related posts, computed once per reload.
+
+- Shared tags score by rarity: a tag on 2 of 50 posts says more than one
+  on 40 (inverse document frequency).
+- Body similarity (cosine over tf-idf term vectors) breaks ties and is the
+  only signal between posts without shared tags.
+- Everything iterates in a fixed order and ties fall back to date and slug,
+  so the same content always yields the same lists.
+*/
+
+// maxRelated is how many related posts are kept per post.
+const maxRelated = 5
+
+// minSimilarity drops untagged matches that share little more than common words.
+const minSimilarity = 0.05
+
+// termWeight is one entry of a sparse, term-sorted tf-idf vector.
+type termWeight struct {
+	term string
+	w    float64
+}
+
+// buildRelated returns, per post index, up to maxRelated related post indexes,
+// best first. text is the plain body text per post (see searchIndex.text).
+func buildRelated(posts []Post, text []string) [][]int {
+	n := len(posts)
+	out := make([][]int, n)
+	if n < 2 {
+		return out
+	}
+
+	// Tag rarity.
+	tagDF := make(map[string]int)
+	tagSets := make([][]string, n)
+	for i, p := range posts {
+		for _, t := range p.Tags {
+			slug := TagSlug(t)
+			tagSets[i] = append(tagSets[i], slug)
+			tagDF[slug]++
+		}
+		sort.Strings(tagSets[i])
+	}
+	tagIDF := func(slug string) float64 { return math.Log(float64(n+1) / float64(tagDF[slug])) }
+
+	vecs := termVectors(text)
+
+	type cand struct {
+		id      int
+		tags    float64
+		content float64
+	}
+	for i := range posts {
+		var cs []cand
+		for j := range posts {
+			if i == j {
+				continue
+			}
+			c := cand{id: j, content: cosine(vecs[i], vecs[j])}
+			for _, slug := range intersect(tagSets[i], tagSets[j]) {
+				c.tags += tagIDF(slug)
+			}
+			if c.tags == 0 && c.content < minSimilarity {
+				continue
+			}
+			cs = append(cs, c)
+		}
+		sort.Slice(cs, func(a, b int) bool {
+			ca, cb := cs[a], cs[b]
+			if ca.tags != cb.tags {
+				return ca.tags > cb.tags
+			}
+			if ca.content != cb.content {
+				return ca.content > cb.content
+			}
+			// posts are date desc, so the lower index is newer.
+			return ca.id < cb.id
+		})
+		for k := 0; k < len(cs) && k < maxRelated; k++ {
+			out[i] = append(out[i], cs[k].id)
+		}
+	}
+	return out
+}
+
+// termVectors builds a normalized tf-idf vector per text, sorted by term.
+func termVectors(text []string) [][]termWeight {
+	tfs := make([]map[string]int, len(text))
+	df := make(map[string]int)
+	for i, t := range text {
+		tf := make(map[string]int)
+		for _, tok := range tokenize(t) {
+			if len(tok) < 3 {
+				continue // "a", "of", "go"... too common to mean much
+			}
+			tf[tok]++
+		}
+		for tok := range tf {
+			df[tok]++
+		}
+		tfs[i] = tf
+	}
+
+	n := float64(len(text))
+	vecs := make([][]termWeight, len(text))
+	for i, tf := range tfs {
+		v := make([]termWeight, 0, len(tf))
+		for tok, c := range tf {
+			idf := math.Log(n / float64(df[tok]))
+			if idf <= 0 {
+				continue // in every post
+			}
+			v = append(v, termWeight{tok, (1 + math.Log(float64(c))) * idf})
+		}
+		sort.Slice(v, func(a, b int) bool { return v[a].term < v[b].term })
+		var norm float64
+		for _, tw := range v {
+			norm += tw.w * tw.w
+		}
+		norm = math.Sqrt(norm)
+		for k := range v {
+			v[k].w /= norm
+		}
+		vecs[i] = v
+	}
+	return vecs
+}
+
+// cosine is the dot product of two normalized, term-sorted vectors.
+func cosine(a, b []termWeight) float64 {
+	var sum float64
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i].term < b[j].term:
+			i++
+		case a[i].term > b[j].term:
+			j++
+		default:
+			sum += a[i].w * b[j].w
+			i++
+			j++
+		}
+	}
+	return sum
+}
+
+// intersect returns the common elements of two sorted slices.
+func intersect(a, b []string) []string {
+	var out []string
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
@@ -0,0 +1,54 @@
+package blog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func relatedSlugs(s *FilesStore, slug string, n int) []string {
+	var out []string
+	for _, p := range s.Related(slug, n) {
+		out = append(out, p.Slug)
+	}
+	return out
+}
+
+func TestFilesStore_Related(t *testing.T) {
+	td := t.TempDir()
+	// "kubernetes" is rare, "go" is everywhere: sharing the rare tag wins.
+	write(t, td, "a.md", "---\ntitle: A\ndate: 2025-08-05\ntags: [go, kubernetes]\n---\nDeploying services.")
+	write(t, td, "b.md", "---\ntitle: B\ndate: 2025-08-04\ntags: [go, kubernetes]\n---\nOperators.")
+	write(t, td, "c.md", "---\ntitle: C\ndate: 2025-08-03\ntags: [go]\n---\nGenerics.")
+	write(t, td, "d.md", "---\ntitle: D\ndate: 2025-08-02\ntags: [go]\n---\nChannels.")
+	// No tags: only content can relate these two.
+	write(t, td, "e.md", "---\ntitle: E\ndate: 2025-08-01\n---\nSourdough starter hydration and sourdough baking.")
+	write(t, td, "f.md", "---\ntitle: F\ndate: 2025-07-01\n---\nMy sourdough starter died.")
+
+	s, err := NewFilesStore(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := relatedSlugs(s, "a", 0), []string{"b", "c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("related(a) = %v, want %v", got, want)
+	}
+	if got := relatedSlugs(s, "a", 1); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Fatalf("related(a, 1) = %v", got)
+	}
+	if got := relatedSlugs(s, "e", 0); !reflect.DeepEqual(got, []string{"f"}) {
+		t.Fatalf("related(e) = %v, want [f]", got)
+	}
+	if s.Related("missing", 3) != nil {
+		t.Fatalf("unknown slug should have no related posts")
+	}
+
+	// Reloading the same content yields the same lists.
+	for range 5 {
+		if err := s.reload(); err != nil {
+			t.Fatal(err)
+		}
+		if got := relatedSlugs(s, "a", 0); !reflect.DeepEqual(got, []string{"b", "c", "d"}) {
+			t.Fatalf("related(a) changed on reload: %v", got)
+		}
+	}
+}
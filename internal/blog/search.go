@@ -0,0 +1,203 @@
+// internal/blog/search.go
+package blog
+
+import (
+	"html"
+	"html/template"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+In-memory full-text search over posts.
+
+- Built once per reload from title, tags, summary and body text.
+- Query terms match indexed terms by prefix ("conc" finds "concurrency").
+- Every query term must match; hits are ranked by summed field weight.
+- Snippets are cut from the body text around the first hit, with matches
+  wrapped in <mark>.
+*/
+
+// SearchResult is one ranked hit.
+type SearchResult struct {
+	Post    Post
+	Score   int
+	Snippet template.HTML // escaped body excerpt with <mark>ed matches
+}
+
+// Field weights: a hit in the title counts far more than one in the body.
+const (
+	weightTitle   = 10
+	weightTags    = 6
+	weightSummary = 3
+	weightBody    = 1
+)
+
+// snippetRunes is the approximate length of a search snippet.
+const snippetRunes = 160
+
+type searchIndex struct {
+	terms    []string               // sorted, for prefix lookup
+	postings map[string]map[int]int // term -> post index -> weight
+	text     []string               // plain body text per post, for snippets
+}
+
+func buildSearchIndex(posts []Post) *searchIndex {
+	si := &searchIndex{
+		postings: make(map[string]map[int]int),
+		text:     make([]string, len(posts)),
+	}
+	add := func(i int, s string, w int) {
+		for _, tok := range tokenize(s) {
+			m := si.postings[tok]
+			if m == nil {
+				m = make(map[int]int)
+				si.postings[tok] = m
+			}
+			m[i] += w
+		}
+	}
+	for i, p := range posts {
+		si.text[i] = plainText(string(p.HTML))
+		add(i, p.Title, weightTitle)
+		add(i, strings.Join(p.Tags, " "), weightTags)
+		add(i, p.Summary, weightSummary)
+		add(i, si.text[i], weightBody)
+	}
+	si.terms = make([]string, 0, len(si.postings))
+	for t := range si.postings {
+		si.terms = append(si.terms, t)
+	}
+	sort.Strings(si.terms)
+	return si
+}
+
+// search ranks posts matching every token of q. limit <= 0 means no limit.
+func (si *searchIndex) search(posts []Post, q string, limit int) []SearchResult {
+	toks := tokenize(q)
+	if len(toks) == 0 {
+		return nil
+	}
+
+	var scores map[int]int
+	for _, tok := range toks {
+		hits := make(map[int]int)
+		for i := sort.SearchStrings(si.terms, tok); i < len(si.terms) && strings.HasPrefix(si.terms[i], tok); i++ {
+			for id, w := range si.postings[si.terms[i]] {
+				hits[id] += w
+			}
+		}
+		if scores == nil {
+			scores = hits
+			continue
+		}
+		// AND: keep posts that matched every token so far.
+		for id := range scores {
+			if w, ok := hits[id]; ok {
+				scores[id] += w
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	ids := make([]int, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	// posts are date desc, so the index breaks ties newest first.
+	sort.Slice(ids, func(a, b int) bool {
+		if scores[ids[a]] != scores[ids[b]] {
+			return scores[ids[a]] > scores[ids[b]]
+		}
+		return ids[a] < ids[b]
+	})
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	out := make([]SearchResult, len(ids))
+	for k, id := range ids {
+		out[k] = SearchResult{
+			Post:    posts[id],
+			Score:   scores[id],
+			Snippet: snippet(si.text[id], toks),
+		}
+	}
+	return out
+}
+
+// tokenize lowercases s and splits it into runs of letters and digits.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// plainText strips tags from rendered HTML and collapses whitespace.
+func plainText(h string) string {
+	var b strings.Builder
+	inTag := false
+	for _, r := range h {
+		switch {
+		case r == '<':
+			inTag = true
+			b.WriteByte(' ')
+		case r == '>' && inTag:
+			inTag = false
+		case !inTag:
+			b.WriteRune(r)
+		}
+	}
+	return strings.Join(strings.Fields(html.UnescapeString(b.String())), " ")
+}
+
+// snippet returns an escaped window of text around the first word matching
+// any token, with every matching word wrapped in <mark>.
+func snippet(text string, toks []string) template.HTML {
+	words := strings.Fields(text)
+	matches := func(w string) bool {
+		for _, wt := range tokenize(w) {
+			for _, t := range toks {
+				if strings.HasPrefix(wt, t) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	first := 0
+	for i, w := range words {
+		if matches(w) {
+			first = i
+			break
+		}
+	}
+	// Start a few words before the hit so it reads in context.
+	start := max(0, first-8)
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("… ")
+	}
+	n := 0
+	i := start
+	for ; i < len(words) && n < snippetRunes; i++ {
+		if i > start {
+			b.WriteByte(' ')
+		}
+		w := template.HTMLEscapeString(words[i])
+		if matches(words[i]) {
+			w = "<mark>" + w + "</mark>"
+		}
+		b.WriteString(w)
+		n += utf8.RuneCountInString(words[i]) + 1
+	}
+	if i < len(words) {
+		b.WriteString(" …")
+	}
+	return template.HTML(b.String())
+}
@@ -0,0 +1,77 @@
+// internal/blog/series.go
+package blog
+
+import (
+	"sort"
+	"time"
+)
+
+/*
+Multi-part series.
+
+Posts join a series with `series: Name` and order themselves with
+`series_order: N`. Posts without an order follow the ordered ones by date.
+Series are keyed by Slugify(name), so spelling variants share a series.
+*/
+
+// PostRef is a lightweight link to another post.
+type PostRef struct {
+	Title string
+	Slug  string
+	Date  time.Time
+}
+
+// Ref returns a link to p.
+func (p Post) Ref() PostRef { return PostRef{Title: p.Title, Slug: p.Slug, Date: p.Date} }
+
+// SeriesNav places a post within its series.
+type SeriesNav struct {
+	Name  string   // display form, from the first post of the series
+	Slug  string   // url id: /blog/series/{Slug}
+	Order int      // series_order from front matter; 0 if unset
+	Index int      // 1-based position
+	Total int      // number of posts in the series
+	Prev  *PostRef // nil for the first part
+	Next  *PostRef // nil for the last part
+}
+
+// indexSeries groups posts by series slug in reading order and completes
+// each member's Series navigation. parseFile sets Name, Slug and Order.
+func indexSeries(posts []Post) map[string][]int {
+	bySeries := make(map[string][]int)
+	for i, p := range posts {
+		if p.Series != nil {
+			bySeries[p.Series.Slug] = append(bySeries[p.Series.Slug], i)
+		}
+	}
+	for _, ids := range bySeries {
+		sort.SliceStable(ids, func(a, b int) bool {
+			pa, pb := posts[ids[a]], posts[ids[b]]
+			oa, ob := pa.Series.Order, pb.Series.Order
+			if (oa > 0) != (ob > 0) {
+				return oa > 0 // ordered parts first
+			}
+			if oa != ob {
+				return oa < ob
+			}
+			if !pa.Date.Equal(pb.Date) {
+				return pa.Date.Before(pb.Date)
+			}
+			return pa.Slug < pb.Slug
+		})
+		name := posts[ids[0]].Series.Name
+		for k, id := range ids {
+			nav := posts[id].Series
+			nav.Name, nav.Index, nav.Total = name, k+1, len(ids)
+			if k > 0 {
+				ref := posts[ids[k-1]].Ref()
+				nav.Prev = &ref
+			}
+			if k < len(ids)-1 {
+				ref := posts[ids[k+1]].Ref()
+				nav.Next = &ref
+			}
+		}
+	}
+	return bySeries
+}
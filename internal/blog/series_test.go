@@ -0,0 +1,55 @@
+package blog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilesStore_Series(t *testing.T) {
+	td := t.TempDir()
+	write(t, td, "p2.md", "---\ntitle: Part Two\ndate: 2025-08-01\nseries: Building a Blog\nseries_order: 2\n---\ntwo")
+	write(t, td, "p1.md", "---\ntitle: Part One\ndate: 2025-08-05\nseries: building a blog\nseries_order: 1\n---\none")
+	write(t, td, "extra.md", "---\ntitle: Epilogue\ndate: 2025-07-01\nseries: Building a Blog\n---\nlast")
+	write(t, td, "solo.md", "---\ntitle: Solo\ndate: 2025-08-02\n---\nsolo")
+
+	s, err := NewFilesStore(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, p := range s.Series("Building a Blog") {
+		got = append(got, p.Slug)
+	}
+	want := []string{"part-one", "part-two", "epilogue"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("series order = %v, want %v", got, want)
+	}
+	if len(s.Series("building-a-blog")) != 3 {
+		t.Fatalf("slug lookup failed")
+	}
+	if s.Series("") != nil || s.Series("nope") != nil {
+		t.Fatalf("unknown series should be empty")
+	}
+
+	two, _ := s.BySlug("part-two")
+	nav := two.Series
+	if nav == nil || nav.Index != 2 || nav.Total != 3 || nav.Slug != "building-a-blog" {
+		t.Fatalf("nav = %+v", nav)
+	}
+	if nav.Name != "building a blog" {
+		t.Fatalf("name = %q, want the first part's spelling", nav.Name)
+	}
+	if nav.Prev == nil || nav.Prev.Slug != "part-one" || nav.Next == nil || nav.Next.Slug != "epilogue" {
+		t.Fatalf("prev/next = %+v / %+v", nav.Prev, nav.Next)
+	}
+
+	one, _ := s.BySlug("part-one")
+	if one.Series.Prev != nil {
+		t.Fatalf("first part has prev %+v", one.Series.Prev)
+	}
+	solo, _ := s.BySlug("solo")
+	if solo.Series != nil {
+		t.Fatalf("solo post has series %+v", solo.Series)
+	}
+}
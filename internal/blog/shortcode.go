@@ -0,0 +1,452 @@
+// internal/blog/shortcode.go
+package blog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+/*
+Shortcodes: reusable snippets inside posts, in Hugo's syntax.
+
+	{{< figure src="diagram.png" caption="How the pieces fit" >}}
+	{{< callout warning >}}
+	Markdown here is **rendered** and passed to the template as .Inner.
+	{{< /callout >}}
+
+A call without a matching closing tag is self-closing. Arguments are bare
+words, "quoted" or `raw` strings, positionally or as key=value. A call
+commented out inside its brackets, opened with {{</* and closed with the
+mirror image, is written out literally, for posts about shortcodes. Inside
+code blocks and code spans calls are left as they are.
+
+parseFile swaps each call for a placeholder and checks its name against the
+templates given to WithShortcodes, so a typo fails the load naming the file
+and line. render executes "shortcodes/<name>" once slugs are final (bundle
+URLs depend on them) and puts the output back in place of the placeholder,
+which keeps it intact whether or not raw HTML is allowed in Markdown.
+*/
+
+// Shortcode is the data a shortcode template executes with.
+type Shortcode struct {
+	Name   string
+	Args   []string          // positional arguments, in order
+	Params map[string]string // key=value arguments
+	Inner  template.HTML     // rendered Markdown between the tags; "" when self-closing
+	Post   PostRef           // the post being rendered
+	Site   any               // as given to WithShortcodes
+
+	base   string // "/blog/{slug}/" for page bundles
+	assets fs.FS  // nil for plain posts
+}
+
+// Arg returns the i-th positional argument, or "".
+func (c Shortcode) Arg(i int) string {
+	if i < 0 || i >= len(c.Args) {
+		return ""
+	}
+	return c.Args[i]
+}
+
+// Get returns the named argument, or "".
+func (c Shortcode) Get(name string) string { return c.Params[name] }
+
+// Require returns the named argument, failing the render when it is missing.
+func (c Shortcode) Require(name string) (string, error) {
+	v := c.Params[name]
+	if v == "" {
+		return "", fmt.Errorf("%s: missing %s=", c.Name, name)
+	}
+	return v, nil
+}
+
+// URL resolves ref against the post's page bundle the way Markdown links
+// are; anything else is returned unchanged.
+func (c Shortcode) URL(ref string) string {
+	if c.assets == nil {
+		return ref
+	}
+	if r, file, ok := bundleRef(ref); ok {
+		if _, err := fs.Stat(c.assets, file); err == nil {
+			return c.base + r
+		}
+	}
+	return ref
+}
+
+// shortcodeTemplate names the template that renders a shortcode.
+func shortcodeTemplate(name string) string { return "shortcodes/" + name }
+
+/************ parsing ************/
+
+// shortcodeCall is one call found in a post.
+type shortcodeCall struct {
+	name   string
+	args   []string
+	params map[string]string
+	line   int    // in the source file
+	inner  []byte // nil when self-closing; nested calls are placeholders
+}
+
+// Placeholders are private-use runes around the call's index: Markdown
+// leaves them alone, and they can't clash with anything a post contains.
+const (
+	placeholderOpen  = "\ue000"
+	placeholderClose = "\ue001"
+)
+
+// placeholderRE matches a placeholder alone in a paragraph (group 1), so
+// block output doesn't end up inside <p>, or anywhere else (group 2).
+var placeholderRE = regexp.MustCompile(`<p>\x{E000}(\d+)\x{E001}</p>\n?|\x{E000}(\d+)\x{E001}`)
+
+func placeholder(i int) string { return placeholderOpen + strconv.Itoa(i) + placeholderClose }
+
+// stripPlaceholders removes placeholders from derived plain text.
+func stripPlaceholders(s string) string {
+	if !strings.Contains(s, placeholderOpen) {
+		return s
+	}
+	return placeholderRE.ReplaceAllString(s, " ")
+}
+
+var (
+	shortcodeOpen  = []byte("{{<")
+	shortcodeClose = []byte(">}}")
+	shortcodeName  = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// shortcodeTag is one parsed {{< ... >}}.
+type shortcodeTag struct {
+	name        string
+	args        []string
+	params      map[string]string
+	closing     bool   // {{< /name >}}
+	selfClosing bool   // {{< name />}}
+	literal     string // a commented-out call, written out as {{< ... >}}
+	size        int    // bytes consumed
+}
+
+// shortcodeScanner collects the calls of one file.
+type shortcodeScanner struct {
+	file  string
+	known func(name string) bool
+	calls []shortcodeCall
+}
+
+// expand replaces the calls in b with placeholders. line is the file line
+// b starts on; errors carry the line of the offending tag.
+func (sc *shortcodeScanner) expand(b []byte, line int) ([]byte, error) {
+	code := codeRanges(b)
+	var out bytes.Buffer
+	for off := 0; ; {
+		i := bytes.Index(b[off:], shortcodeOpen)
+		if i < 0 {
+			out.Write(b[off:])
+			return out.Bytes(), nil
+		}
+		i += off
+		out.Write(b[off:i])
+		line += bytes.Count(b[off:i], []byte("\n"))
+
+		// Calls inside code are text, but a commented-out call still
+		// unwraps so code samples can keep using it.
+		if inCode(code, i) {
+			n := len(shortcodeOpen)
+			if tag, err := parseShortcodeTag(b[i:]); err == nil && tag.literal != "" {
+				out.WriteString(tag.literal)
+				n = tag.size
+			} else {
+				out.Write(shortcodeOpen)
+			}
+			line += bytes.Count(b[i:i+n], []byte("\n"))
+			off = i + n
+			continue
+		}
+
+		tag, err := parseShortcodeTag(b[i:])
+		if err != nil {
+			return nil, sc.errorf(line, "%w", err)
+		}
+		switch {
+		case tag.literal != "":
+			out.WriteString(tag.literal)
+			line += bytes.Count(b[i:i+tag.size], []byte("\n"))
+			off = i + tag.size
+			continue
+		case tag.closing:
+			return nil, sc.errorf(line, "closing %q without an opening tag", tag.name)
+		case !sc.known(tag.name):
+			return nil, sc.errorf(line, "unknown shortcode %q", tag.name)
+		}
+
+		call := shortcodeCall{name: tag.name, args: tag.args, params: tag.params, line: line}
+		end := i + tag.size
+		if !tag.selfClosing {
+			if at, n, ok := findClosingTag(b, end, tag.name, code); ok {
+				innerLine := line + bytes.Count(b[i:end], []byte("\n"))
+				inner, err := sc.expand(b[end:at], innerLine)
+				if err != nil {
+					return nil, err
+				}
+				call.inner = append([]byte{}, inner...)
+				end = at + n
+			}
+		}
+		out.WriteString(placeholder(len(sc.calls)))
+		sc.calls = append(sc.calls, call)
+		line += bytes.Count(b[i:end], []byte("\n"))
+		off = end
+	}
+}
+
+func (sc *shortcodeScanner) errorf(line int, format string, args ...any) error {
+	return fmt.Errorf("shortcode %s:%d: %w", sc.file, line, fmt.Errorf(format, args...))
+}
+
+// findClosingTag finds {{< /name >}} for a call whose opening tag ends at
+// off, skipping over nested calls of the same name and tags inside code.
+// at is the offset of the closing tag in b.
+func findClosingTag(b []byte, off int, name string, code [][2]int) (at, size int, ok bool) {
+	depth := 0
+	for {
+		i := bytes.Index(b[off:], shortcodeOpen)
+		if i < 0 {
+			return 0, 0, false
+		}
+		off += i
+		tag, err := parseShortcodeTag(b[off:])
+		if err != nil || inCode(code, off) {
+			off += len(shortcodeOpen)
+			continue
+		}
+		switch {
+		case tag.literal != "" || tag.name != name:
+		case tag.closing && depth == 0:
+			return off, tag.size, true
+		case tag.closing:
+			depth--
+		case !tag.selfClosing:
+			depth++
+		}
+		off += tag.size
+	}
+}
+
+// codeRanges returns the byte ranges of src that Markdown treats as code:
+// fenced and indented blocks and code spans. Shortcodes there are text.
+func codeRanges(src []byte) [][2]int {
+	var out [][2]int
+	doc := goldmark.DefaultParser().Parse(text.NewReader(src))
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			if lines := n.Lines(); lines.Len() > 0 {
+				out = append(out, [2]int{lines.At(0).Start, lines.At(lines.Len() - 1).Stop})
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.CodeSpan:
+			first, last := n.FirstChild(), n.LastChild()
+			if t, ok := first.(*ast.Text); ok {
+				if u, ok := last.(*ast.Text); ok {
+					out = append(out, [2]int{t.Segment.Start, u.Segment.Stop})
+				}
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return out
+}
+
+// inCode reports whether offset i falls in one of the code ranges.
+func inCode(code [][2]int, i int) bool {
+	for _, r := range code {
+		if i >= r[0] && i < r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// parseShortcodeTag parses the tag at the start of b.
+func parseShortcodeTag(b []byte) (shortcodeTag, error) {
+	end := bytes.Index(b, shortcodeClose)
+	if end < 0 {
+		return shortcodeTag{}, errors.New("unterminated shortcode, want >}}")
+	}
+	tag := shortcodeTag{size: end + len(shortcodeClose)}
+	body := strings.TrimSpace(string(b[len(shortcodeOpen):end]))
+
+	if c, ok := strings.CutPrefix(body, "/*"); ok {
+		if c, ok = strings.CutSuffix(c, "*/"); !ok {
+			return tag, errors.New("unterminated shortcode comment, want */>}}")
+		}
+		tag.literal = "{{<" + c + ">}}"
+		return tag, nil
+	}
+	if c, ok := strings.CutPrefix(body, "/"); ok {
+		tag.closing = true
+		body = strings.TrimSpace(c)
+	} else if c, ok := strings.CutSuffix(body, "/"); ok {
+		tag.selfClosing = true
+		body = strings.TrimSpace(c)
+	}
+
+	name, rest := body, ""
+	if i := strings.IndexAny(body, " \t\n"); i >= 0 {
+		name, rest = body[:i], body[i:]
+	}
+	if !shortcodeName.MatchString(name) {
+		return tag, fmt.Errorf("bad shortcode name %q", name)
+	}
+	tag.name = name
+	if tag.closing {
+		if strings.TrimSpace(rest) != "" {
+			return tag, fmt.Errorf("closing %q takes no arguments", name)
+		}
+		return tag, nil
+	}
+	var err error
+	tag.args, tag.params, err = parseShortcodeArgs(rest)
+	if err != nil {
+		return tag, fmt.Errorf("%s: %w", name, err)
+	}
+	return tag, nil
+}
+
+// parseShortcodeArgs splits `a "b c" key=d key2="e f"` into positional
+// and named arguments.
+func parseShortcodeArgs(s string) ([]string, map[string]string, error) {
+	var args []string
+	params := map[string]string{}
+	for {
+		s = strings.TrimLeft(s, " \t\n")
+		if s == "" {
+			return args, params, nil
+		}
+		key := ""
+		if i := strings.IndexAny(s, "= \t\n\"`"); i > 0 && s[i] == '=' {
+			key, s = s[:i], s[i+1:]
+		}
+		var v string
+		switch {
+		case strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "`"):
+			q, err := strconv.QuotedPrefix(s)
+			if err != nil {
+				return nil, nil, fmt.Errorf("unterminated string %s", s)
+			}
+			s = s[len(q):]
+			v, _ = strconv.Unquote(q)
+		default:
+			i := strings.IndexAny(s, " \t\n")
+			if i < 0 {
+				i = len(s)
+			}
+			v, s = s[:i], s[i:]
+		}
+		if key != "" {
+			params[key] = v
+		} else {
+			args = append(args, v)
+		}
+	}
+}
+
+// shortcodeWords counts the words the calls contribute in their inner text.
+func shortcodeWords(calls []shortcodeCall) int {
+	n := 0
+	for _, c := range calls {
+		n += len(strings.Fields(stripPlaceholders(string(c.inner))))
+	}
+	return n
+}
+
+/************ rendering ************/
+
+// shortcodeRenderer fills one post's placeholders with template output.
+type shortcodeRenderer struct {
+	t     *template.Template
+	md    goldmark.Markdown
+	calls []shortcodeCall
+	file  string
+	links func(ast.Node) error // resolves wiki links; see FilesStore.resolveWikiLinks
+	data  Shortcode            // Post, Site and bundle fields shared by every call
+}
+
+// fill replaces the placeholders in html.
+func (r *shortcodeRenderer) fill(html []byte) ([]byte, error) {
+	var err error
+	out := placeholderRE.ReplaceAllFunc(html, func(m []byte) []byte {
+		if err != nil {
+			return nil
+		}
+		sub := placeholderRE.FindSubmatch(m)
+		i, _ := strconv.Atoi(string(sub[1]) + string(sub[2]))
+		var b []byte
+		b, err = r.call(i)
+		return b
+	})
+	return out, err
+}
+
+func (r *shortcodeRenderer) call(i int) ([]byte, error) {
+	if i >= len(r.calls) {
+		return nil, fmt.Errorf("shortcode %s: no call %d", r.file, i)
+	}
+	c := r.calls[i]
+	data := r.data
+	data.Name, data.Args, data.Params = c.name, c.args, c.params
+	if c.inner != nil {
+		inner, err := r.inner(c.inner)
+		if err != nil {
+			return nil, err
+		}
+		data.Inner = template.HTML(inner)
+	}
+	var out bytes.Buffer
+	if err := r.t.ExecuteTemplate(&out, shortcodeTemplate(c.name), data); err != nil {
+		return nil, fmt.Errorf("shortcode %s:%d: %w", r.file, c.line, err)
+	}
+	return out.Bytes(), nil
+}
+
+// inner renders a call's inner Markdown, resolving wiki links, bundle links
+// and nested calls. Inner text on the same line as its tags renders inline,
+// without a wrapping <p>.
+func (r *shortcodeRenderer) inner(src []byte) ([]byte, error) {
+	doc := r.md.Parser().Parse(text.NewReader(src))
+	if r.links != nil {
+		if err := r.links(doc); err != nil {
+			return nil, err
+		}
+	}
+	if r.data.assets != nil {
+		rewriteBundleLinks(doc, r.data.base, r.data.assets)
+	}
+	var b bytes.Buffer
+	if err := r.md.Renderer().Render(&b, src, doc); err != nil {
+		return nil, fmt.Errorf("shortcode %s: %w", r.file, err)
+	}
+	out := b.Bytes()
+	if !bytes.Contains(src, []byte("\n")) {
+		if p, ok := bytes.CutPrefix(out, []byte("<p>")); ok {
+			if p, ok = bytes.CutSuffix(p, []byte("</p>\n")); ok && !bytes.Contains(p, []byte("<p>")) {
+				out = p
+			}
+		}
+	}
+	return r.fill(out)
+}
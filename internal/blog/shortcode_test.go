@@ -0,0 +1,137 @@
+package blog
+
+import (
+	"html/template"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testShortcodes = template.Must(template.New("").Parse(`
+{{- define "shortcodes/note" }}<aside class="note {{ .Arg 0 }}">{{ .Inner }}</aside>{{ end }}
+{{- define "shortcodes/img" }}<img src="{{ .URL (.Require "src") }}" alt="{{ .Get "alt" }}">{{ end }}
+{{- define "shortcodes/site" }}<b>{{ .Site }} / {{ .Post.Slug }}</b>{{ end }}
+`))
+
+func shortcodeStore(t *testing.T, files map[string]string) (*FilesStore, error) {
+	t.Helper()
+	td := t.TempDir()
+	for name, body := range files {
+		path := filepath.Join(td, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		write(t, filepath.Dir(path), filepath.Base(path), body)
+	}
+	return NewFilesStore(td, WithShortcodes(testShortcodes, "My Site"))
+}
+
+func TestShortcodes_Render(t *testing.T) {
+	s, err := shortcodeStore(t, map[string]string{
+		"a.md": "---\ntitle: A\n---\n" +
+			"Intro with {{< site >}} inline.\n\n" +
+			"{{< note warning >}}\nSome **bold** text.\n\n{{< note >}}nested{{< /note >}}\n{{< /note >}}\n\n" +
+			"Show {{</* note */>}} literally.\n",
+		"trip/index.md": "---\ntitle: Trip\n---\n{{< img src=\"map.png\" alt=\"The map\" >}}\n",
+		"trip/map.png":  "png",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, _ := s.BySlug("a")
+	html := string(p.HTML)
+	for _, want := range []string{
+		"<p>Intro with <b>My Site / a</b> inline.</p>",
+		`<aside class="note warning"><p>Some <strong>bold</strong> text.</p>`,
+		`<aside class="note ">nested</aside></aside>`,
+		"Show {{&lt; note &gt;}} literally.",
+	} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("missing %q in:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "<p><aside") || strings.Contains(html, placeholderOpen) {
+		t.Fatalf("placeholder left behind or block output wrapped in <p>:\n%s", html)
+	}
+	if p.Summary != "Intro with inline." {
+		t.Fatalf("summary=%q", p.Summary)
+	}
+
+	trip, _ := s.BySlug("trip")
+	if want := `<img src="/blog/trip/map.png" alt="The map">`; !strings.Contains(string(trip.HTML), want) {
+		t.Fatalf("bundle URL not resolved: %s", trip.HTML)
+	}
+}
+
+func TestShortcodes_NotInCode(t *testing.T) {
+	s, err := shortcodeStore(t, map[string]string{
+		"a.md": "---\ntitle: A\ntoc: true\n---\n" +
+			"## Setup {{< site >}} notes\n\n" +
+			"Inline `{{< nope >}}` and {{< site >}}.\n\n" +
+			"```\n{{< note >}}\nunclosed {{< /missing\n{{</* note */>}}\n```\n\n" +
+			"    {{< img >}}\n\n" +
+			"{{< note >}}`{{< /note >}}` stays{{< /note >}}\n",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, _ := s.BySlug("a")
+	html := string(p.HTML)
+	for _, want := range []string{
+		"<code>{{&lt; nope &gt;}}</code> and <b>My Site / a</b>.",
+		"unclosed {{&lt; /missing",
+		`<span class="cl">{{&lt; note &gt;}}`,
+		"<pre><code>{{&lt; img &gt;}}\n</code></pre>",
+		`<aside class="note "><code>{{&lt; /note &gt;}}</code> stays</aside>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("missing %q in:\n%s", want, html)
+		}
+	}
+	if len(p.TOC) != 1 || p.TOC[0].Title != "Setup notes" {
+		t.Fatalf("toc = %+v, want the heading without its shortcode", p.TOC)
+	}
+}
+
+func TestShortcodes_ErrorsNameFileAndLine(t *testing.T) {
+	cases := map[string]struct{ body, want string }{
+		"unknown":   {"---\ntitle: X\n---\nok\n\n{{< nope >}}\n", `shortcode bad.md:6: unknown shortcode "nope"`},
+		"crlf":      {"---\r\ntitle: X\r\n---\r\n{{< nope >}}\r\n", `shortcode bad.md:4: unknown shortcode "nope"`},
+		"closing":   {"text\n{{< /note >}}\n", `shortcode bad.md:2: closing "note" without an opening tag`},
+		"unclosed":  {"{{< note\n", "shortcode bad.md:1: unterminated shortcode"},
+		"bad quote": {"\n\n{{< note \"x >}}\n", "shortcode bad.md:3: note: unterminated string"},
+		"missing":   {"{{< img >}}\n", "missing src="},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := shortcodeStore(t, map[string]string{"bad.md": c.body})
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Fatalf("err=%v, want %q", err, c.want)
+			}
+		})
+	}
+}
+
+func TestShortcodes_NoneConfigured(t *testing.T) {
+	td := t.TempDir()
+	write(t, td, "a.md", "{{< note >}}\n")
+	if _, err := NewFilesStore(td); err == nil || !strings.Contains(err.Error(), `unknown shortcode "note"`) {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestParseShortcodeArgs(t *testing.T) {
+	args, params, err := parseShortcodeArgs(` warning "two words" title="A \"T\"" w=640 raw=` + "`x y`")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"warning", "two words"}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args=%q, want %q", args, want)
+	}
+	if want := map[string]string{"title": `A "T"`, "w": "640", "raw": "x y"}; !reflect.DeepEqual(params, want) {
+		t.Fatalf("params=%q, want %q", params, want)
+	}
+}
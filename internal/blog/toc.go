@@ -0,0 +1,145 @@
+// internal/blog/toc.go
+package blog
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+/*
+Table of contents and heading anchors.
+
+Heading IDs come from goldmark's auto heading IDs (always on, de-duplicated
+with -1, -2 suffixes). The HeadingAnchors extension adds a hover link to
+each heading, and extractTOC turns headings into a nested TOC tree.
+*/
+
+// TOCEntry is one heading in a post's table of contents.
+type TOCEntry struct {
+	ID       string
+	Title    string
+	Level    int // 1-6
+	Children []TOCEntry
+}
+
+// Default TOC depth: h2 through h3 (h1 is the post title).
+const (
+	DefaultTOCMin = 2
+	DefaultTOCMax = 3
+)
+
+// HeadingAnchors is a goldmark extension that appends a "#" permalink to
+// every heading that has an id.
+var HeadingAnchors goldmark.Extender = headingAnchors{}
+
+type headingAnchors struct{}
+
+func (headingAnchors) Extend(m goldmark.Markdown) {
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(headingRenderer{}, 100)))
+}
+
+type headingRenderer struct{}
+
+func (headingRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindHeading, renderHeading)
+}
+
+func renderHeading(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ast.Heading)
+	if entering {
+		_, _ = w.WriteString("<h")
+		_ = w.WriteByte("0123456"[n.Level])
+		if n.Attributes() != nil {
+			html.RenderAttributes(w, node, html.HeadingAttributeFilter)
+		}
+		_ = w.WriteByte('>')
+		return ast.WalkContinue, nil
+	}
+	if id, ok := headingID(n); ok {
+		_, _ = w.WriteString(`<a class="heading-anchor" href="#`)
+		_, _ = w.Write(util.EscapeHTML([]byte(id)))
+		_, _ = w.WriteString(`" aria-label="Link to this section">#</a>`)
+	}
+	_, _ = w.WriteString("</h")
+	_ = w.WriteByte("0123456"[n.Level])
+	_, _ = w.WriteString(">\n")
+	return ast.WalkContinue, nil
+}
+
+func headingID(n *ast.Heading) (string, bool) {
+	v, ok := n.AttributeString("id")
+	if !ok {
+		return "", false
+	}
+	switch id := v.(type) {
+	case []byte:
+		return string(id), len(id) > 0
+	case string:
+		return id, id != ""
+	}
+	return "", false
+}
+
+// extractTOC collects headings between levels min and max (inclusive) into
+// a tree. A heading deeper than its predecessor nests under it; skipped
+// levels (h2 -> h4) still nest one step.
+func extractTOC(doc ast.Node, src []byte, min, max int) []TOCEntry {
+	var flat []TOCEntry
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		h, ok := n.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+		if h.Level >= min && h.Level <= max {
+			if id, ok := headingID(h); ok {
+				flat = append(flat, TOCEntry{ID: id, Title: tocTitle(h, src), Level: h.Level})
+			}
+		}
+		return ast.WalkSkipChildren, nil
+	})
+	out, _ := nestTOC(flat, 0, 0)
+	return out
+}
+
+// tocTitle is a heading's plain text, less any shortcode placeholders.
+func tocTitle(h *ast.Heading, src []byte) string {
+	return strings.Join(strings.Fields(stripPlaceholders(nodeText(h, src))), " ")
+}
+
+// nestTOC consumes flat[i:] while entries are deeper than the parent level,
+// returning them as siblings and the index where it stopped.
+func nestTOC(flat []TOCEntry, i, parent int) ([]TOCEntry, int) {
+	var out []TOCEntry
+	for i < len(flat) && flat[i].Level > parent {
+		e := flat[i]
+		e.Children, i = nestTOC(flat, i+1, e.Level)
+		out = append(out, e)
+	}
+	return out, i
+}
+
+// nodeText returns the plain text of an inline tree (e.g. a heading).
+func nodeText(n ast.Node, src []byte) string {
+	var b strings.Builder
+	_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch t := c.(type) {
+		case *ast.Text:
+			b.Write(t.Segment.Value(src))
+			if t.SoftLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(t.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,98 @@
+package blog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const tocDoc = `---
+title: "Guide"
+---
+# Guide
+
+## Setup
+
+### Install
+
+### Install
+
+#### Deep
+
+## Usage
+
+Text.
+`
+
+func tocShape(entries []TOCEntry) []string {
+	var out []string
+	var walk func([]TOCEntry, string)
+	walk = func(es []TOCEntry, indent string) {
+		for _, e := range es {
+			out = append(out, indent+e.ID)
+			walk(e.Children, indent+"  ")
+		}
+	}
+	walk(entries, "")
+	return out
+}
+
+func TestFilesStore_TOC(t *testing.T) {
+	td := t.TempDir()
+	write(t, td, "guide.md", tocDoc)
+
+	s, err := NewFilesStore(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, _ := s.BySlug("guide")
+
+	want := []string{"setup", "  install", "  install-1", "usage"}
+	if got := tocShape(p.TOC); !reflect.DeepEqual(got, want) {
+		t.Fatalf("TOC = %q, want %q", got, want)
+	}
+	if p.TOC[0].Title != "Setup" || p.TOC[0].Level != 2 {
+		t.Fatalf("entry = %+v", p.TOC[0])
+	}
+	html := string(p.HTML)
+	if !strings.Contains(html, `<h3 id="install-1">Install<a class="heading-anchor" href="#install-1"`) {
+		t.Fatalf("heading id/anchor missing: %s", html)
+	}
+
+	// Depth is configurable.
+	s2, err := NewFilesStore(td, WithTOCDepth(2, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2, _ := s2.BySlug("guide")
+	want = []string{"setup", "  install", "  install-1", "    deep", "usage"}
+	if got := tocShape(p2.TOC); !reflect.DeepEqual(got, want) {
+		t.Fatalf("TOC(2,4) = %q, want %q", got, want)
+	}
+}
+
+func TestFilesStore_TOCDisabled(t *testing.T) {
+	td := t.TempDir()
+	write(t, td, "guide.md", strings.Replace(tocDoc, `title: "Guide"`, "title: \"Guide\"\ntoc: false", 1))
+
+	s, err := NewFilesStore(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, _ := s.BySlug("guide")
+	if p.TOC != nil {
+		t.Fatalf("TOC = %+v, want nil with toc: false", p.TOC)
+	}
+	if !strings.Contains(string(p.HTML), `id="setup"`) {
+		t.Fatalf("heading ids should remain: %s", p.HTML)
+	}
+}
+
+func TestNestTOC_SkippedLevels(t *testing.T) {
+	flat := []TOCEntry{{ID: "a", Level: 3}, {ID: "b", Level: 2}, {ID: "c", Level: 4}, {ID: "d", Level: 3}}
+	got, _ := nestTOC(flat, 0, 0)
+	want := []string{"a", "b", "  c", "  d"}
+	if shape := tocShape(got); !reflect.DeepEqual(shape, want) {
+		t.Fatalf("nest = %q, want %q", shape, want)
+	}
+}
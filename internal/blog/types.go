@@ -3,6 +3,7 @@ package blog
 
 import (
 	"html/template"
+	"io/fs"
 	"time"
 )
 
@@ -10,15 +11,96 @@ type Post struct {
 	Title   string
 	Slug    string        // url id, e.g. "go-stdlib-web"
 	Date    time.Time
+	Updated time.Time     // last significant edit; zero if never updated
 	Tags    []string
 	Draft   bool
-	Summary string
+	Summary string        // front matter, else derived from <!--more--> or the first paragraph
 	HTML    template.HTML // rendered markdown
+	TOC     []TOCEntry    // nested headings; nil when disabled with `toc: false`
+	Series  *SeriesNav    // nil when the post is not part of a series
+	Aliases []string      // old slugs that redirect here
+	Source  string        // file the post was loaded from, relative to the content dir (slash-separated)
+
+	WordCount   int
+	ReadingTime int // minutes, rounded; at least 1 for non-empty posts
+
+	Authors     []Author
+	Cover       *Image // nil when unset
+	Canonical   string // absolute URL of the original when cross-posted; "" means this page
+	Description string // for meta tags; "" falls back to Summary
+	Lang        string // BCP 47 tag; "" means the site default
+
+	Backlinks []Post // published posts that wiki-link here, date desc
+
+	links []string // slugs this post wiki-links to, in order (see wikilink.go)
+}
+
+// MetaDescription returns Description, or Summary when unset.
+func (p Post) MetaDescription() string {
+	if p.Description != "" {
+		return p.Description
+	}
+	return p.Summary
+}
+
+// Author is a post author as configured for the site.
+type Author struct {
+	ID     string // referenced from front matter
+	Name   string
+	Avatar string // image URL
+	Links  []Link
+}
+
+// Link is a labelled URL.
+type Link struct {
+	Label string
+	URL   string
+}
+
+// Image is an image with alt text.
+type Image struct {
+	Src string
+	Alt string
+}
+
+// TagLinks returns the post's tags with their URL slugs (Count is unset).
+func (p Post) TagLinks() []Tag {
+	out := make([]Tag, len(p.Tags))
+	for i, t := range p.Tags {
+		out[i] = Tag{Name: t, Slug: TagSlug(t)}
+	}
+	return out
+}
+
+// Tag is a tag as listed on the tag archive.
+type Tag struct {
+	Name  string // display form, e.g. "Go Lang"
+	Slug  string // url id, e.g. "go-lang"
+	Count int    // number of posts carrying the tag
+}
+
+// LastMod returns when the post last changed: Updated if set, else Date.
+func (p Post) LastMod() time.Time {
+	if p.Updated.After(p.Date) {
+		return p.Updated
+	}
+	return p.Date
 }
 
 type Store interface {
 	All() []Post               // sorted desc by Date, no drafts (unless configured)
+	Page(page, size int) ([]Post, int) // 1-based page of All() plus total post count
 	BySlug(slug string) (Post, bool)
-	ByTag(tag string) []Post   // optional; can stub for now
+	Preview(slug string) (Post, bool) // unpublished posts only, for signed previews
+	Alias(slug string) (string, bool) // canonical slug for an old one
+	BundleFS(slug string) (fs.FS, bool) // colocated assets of a page bundle, published or not
+	ByTag(tag string) []Post   // display or slug form, sorted desc by Date
+	Tags() []Tag               // most used first
+	Search(query string, limit int) []SearchResult // prefix match, best first
+	Series(name string) []Post // display or slug form, in reading order
+	Related(slug string, n int) []Post // by shared tags, then content; best first
+	Archive() Archive                  // post counts by year and month
+	ByDate(year int, month time.Month) []Post // month 0: whole year; year 0: undated
+	Graph() Graph                             // wiki links between published posts
 }
 
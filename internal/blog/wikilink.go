@@ -0,0 +1,258 @@
+// internal/blog/wikilink.go
+package blog
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+/*
+Wiki-style links between posts, and the backlinks they imply.
+
+	[[go-stdlib-web]]             by slug; the text is the post's title
+	[[Go stdlib for the web]]     by title, ignoring case and spacing
+	[[go-stdlib-web|this post]]   with link text
+
+The parser only marks the links; FilesStore resolves them once slugs are
+final and fails the load when a target matches no post, or isn't published
+while the linking post is, so a renamed or unpublished post can't leave dead
+links behind. Rendered without a store they are plain
+text. Every resolved link is an edge of the graph behind Post.Backlinks
+and Store.Graph.
+*/
+
+// WikiLinks is a goldmark extension that parses [[target]] and
+// [[target|text]] links for FilesStore to resolve.
+var WikiLinks goldmark.Extender = wikiLinks{}
+
+type wikiLinks struct{}
+
+func (wikiLinks) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		// Ahead of regular links (200), which also start with "[".
+		parser.WithInlineParsers(util.Prioritized(wikiLinkParser{}, 199)),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(wikiLinkRenderer{}, 100)))
+}
+
+var kindWikiLink = ast.NewNodeKind("WikiLink")
+
+// wikiLink is an unresolved [[target|text]]; its child is the text (the
+// target when none is given).
+type wikiLink struct {
+	ast.BaseInline
+	target   string
+	hasLabel bool
+}
+
+func (n *wikiLink) Kind() ast.NodeKind { return kindWikiLink }
+
+func (n *wikiLink) Dump(src []byte, level int) {
+	ast.DumpHelper(n, src, level, map[string]string{"Target": n.target}, nil)
+}
+
+type wikiLinkParser struct{}
+
+func (wikiLinkParser) Trigger() []byte { return []byte{'['} }
+
+func (wikiLinkParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, seg := block.PeekLine()
+	if !bytes.HasPrefix(line, []byte("[[")) {
+		return nil
+	}
+	end := bytes.Index(line, []byte("]]"))
+	if end < 0 {
+		return nil
+	}
+	inner := line[2:end]
+	if bytes.ContainsAny(inner, "[]\n") {
+		return nil
+	}
+	target, label, hasLabel := bytes.Cut(inner, []byte("|"))
+	start := 2
+	if hasLabel {
+		start += len(target) + 1
+	} else {
+		label = target
+	}
+	name := strings.Join(strings.Fields(string(target)), " ")
+	if name == "" || len(bytes.TrimSpace(label)) == 0 {
+		return nil
+	}
+	block.Advance(end + 2)
+
+	n := &wikiLink{target: name, hasLabel: hasLabel}
+	t := text.NewSegment(seg.Start+start, seg.Start+start+len(label))
+	t = t.TrimLeftSpace(block.Source())
+	t = t.TrimRightSpace(block.Source())
+	n.AppendChild(n, ast.NewTextSegment(t))
+	return n
+}
+
+// wikiLinkRenderer renders links nobody resolved as their text.
+type wikiLinkRenderer struct{}
+
+func (wikiLinkRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindWikiLink, func(util.BufWriter, []byte, ast.Node, bool) (ast.WalkStatus, error) {
+		return ast.WalkContinue, nil
+	})
+}
+
+/************ resolving ************/
+
+// postFinder looks up wiki link targets among every loaded post.
+type postFinder struct {
+	bySlug  map[string]int
+	byTitle map[string][]int // normalized title -> post indexes
+}
+
+func newPostFinder(posts []Post) postFinder {
+	f := postFinder{
+		bySlug:  make(map[string]int, len(posts)),
+		byTitle: make(map[string][]int, len(posts)),
+	}
+	for i, p := range posts {
+		f.bySlug[p.Slug] = i
+		key := titleKey(p.Title)
+		f.byTitle[key] = append(f.byTitle[key], i)
+	}
+	return f
+}
+
+// titleKey compares titles regardless of case and spacing.
+func titleKey(s string) string { return strings.ToLower(strings.Join(strings.Fields(s), " ")) }
+
+// find resolves a target: a slug first, then a title shared by no other post.
+func (f postFinder) find(target string) (int, error) {
+	if i, ok := f.bySlug[target]; ok {
+		return i, nil
+	}
+	switch ids := f.byTitle[titleKey(target)]; len(ids) {
+	case 0:
+		return 0, fmt.Errorf("[[%s]] matches no post slug or title", target)
+	case 1:
+		return ids[0], nil
+	default:
+		return 0, fmt.Errorf("[[%s]] matches %d posts by title; link by slug instead", target, len(ids))
+	}
+}
+
+// resolveWikiLinks turns the wiki links in a document of posts[i] (its body,
+// or a shortcode's inner Markdown) into links to /blog/{slug} and adds their
+// targets to Post.links.
+func (s *FilesStore) resolveWikiLinks(posts []Post, i int, doc ast.Node, f postFinder, now time.Time) error {
+	var found []*wikiLink
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if wl, ok := n.(*wikiLink); ok && entering {
+			found = append(found, wl)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	p := &posts[i]
+	for _, wl := range found {
+		j, err := f.find(wl.target)
+		if err != nil {
+			return fmt.Errorf("wiki link %s: %w", p.Source, err)
+		}
+		target := posts[j]
+		if s.hiddenWhileLive(*p, target, now) {
+			return fmt.Errorf("wiki link %s: [[%s]] is %s, which is not published while this post is", p.Source, wl.target, target.Source)
+		}
+
+		link := ast.NewLink()
+		link.Destination = []byte("/blog/" + target.Slug)
+		if wl.hasLabel {
+			for c := wl.FirstChild(); c != nil; c = wl.FirstChild() {
+				link.AppendChild(link, c)
+			}
+		} else {
+			link.AppendChild(link, ast.NewString([]byte(target.Title)))
+		}
+		wl.Parent().ReplaceChild(wl.Parent(), wl, link)
+
+		if !slices.Contains(p.links, target.Slug) {
+			p.links = append(p.links, target.Slug)
+		}
+	}
+	return nil
+}
+
+// hiddenWhileLive reports whether target is unpublished at some time p is
+// published, so that a link from p to it would be a 404: target is a draft,
+// or scheduled after p goes live. Nothing is hidden when drafts are shown.
+func (s *FilesStore) hiddenWhileLive(p, target Post, now time.Time) bool {
+	if s.showDrafts || p.Draft {
+		return false
+	}
+	if target.Draft {
+		return true
+	}
+	live := now
+	if p.Date.After(live) {
+		live = p.Date
+	}
+	return target.Date.After(live)
+}
+
+/************ graph ************/
+
+// Graph is the published posts and the wiki links between them.
+type Graph struct {
+	Nodes []GraphNode // date desc, like All
+	Edges []GraphEdge // in node order, then link order
+}
+
+// GraphNode is a post in the link graph.
+type GraphNode struct {
+	Slug  string
+	Title string
+	Tags  []string
+}
+
+// GraphEdge is a wiki link from one post to another, by slug.
+type GraphEdge struct {
+	From string
+	To   string
+}
+
+// indexLinks fills in Post.Backlinks (date desc) and returns the link
+// graph. Links to posts that are not published, and links from a post to
+// itself, are left out of both.
+func indexLinks(posts []Post, bySlug map[string]int) Graph {
+	g := Graph{Nodes: make([]GraphNode, len(posts))}
+	refs := make([][]int, len(posts))
+	for i, p := range posts {
+		g.Nodes[i] = GraphNode{Slug: p.Slug, Title: p.Title, Tags: p.Tags}
+		for _, to := range p.links {
+			j, ok := bySlug[to]
+			if !ok || j == i {
+				continue
+			}
+			g.Edges = append(g.Edges, GraphEdge{From: p.Slug, To: to})
+			refs[j] = append(refs[j], i)
+		}
+	}
+	// Backlinks hold plain copies so they don't nest further backlinks.
+	plain := append([]Post(nil), posts...)
+	for j, ids := range refs {
+		if len(ids) == 0 {
+			continue
+		}
+		posts[j].Backlinks = make([]Post, len(ids))
+		for k, i := range ids {
+			posts[j].Backlinks[k] = plain[i]
+		}
+	}
+	return g
+}
@@ -0,0 +1,140 @@
+package blog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWikiLinks_ResolveAndBacklink(t *testing.T) {
+	td := t.TempDir()
+	write(t, td, "a.md", "---\ntitle: Alpha\ndate: 2025-08-01\n---\nSee [[beta]] and [[gamma post|the third]], again [[Beta]].\n\n`[[not a link]]`\n")
+	write(t, td, "b.md", "---\ntitle: Beta\ndate: 2025-08-02\n---\nBack to [[  ALPHA ]] and myself: [[beta]].\n")
+	write(t, td, "c.md", "---\ntitle: Gamma Post\ndate: 2025-08-03\n---\nNothing here links out, [[beta|except this]].\n")
+	write(t, td, "d.md", "---\ntitle: Delta\ndraft: true\n---\nDrafts don't count: [[alpha]].\n")
+
+	s, err := NewFilesStore(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, _ := s.BySlug("alpha")
+	html := string(a.HTML)
+	for _, want := range []string{
+		`See <a href="/blog/beta">Beta</a> and <a href="/blog/gamma-post">the third</a>, again <a href="/blog/beta">Beta</a>.`,
+		`<code>[[not a link]]</code>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("missing %q in:\n%s", want, html)
+		}
+	}
+
+	backlinks := func(slug string) []string {
+		p, _ := s.BySlug(slug)
+		var out []string
+		for _, b := range p.Backlinks {
+			out = append(out, b.Slug)
+		}
+		return out
+	}
+	if got, want := backlinks("beta"), []string{"gamma-post", "alpha"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("beta backlinks = %v, want %v (date desc, no self link)", got, want)
+	}
+	if got, want := backlinks("alpha"), []string{"beta"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("alpha backlinks = %v, want %v (no drafts)", got, want)
+	}
+
+	want := []GraphEdge{{"gamma-post", "beta"}, {"beta", "alpha"}, {"alpha", "beta"}, {"alpha", "gamma-post"}}
+	if g := s.Graph(); len(g.Nodes) != 3 || !reflect.DeepEqual(g.Edges, want) {
+		t.Fatalf("graph = %+v, want 3 nodes and edges %v", g, want)
+	}
+}
+
+func TestWikiLinks_ScheduledPostsJoinGraphWhenLive(t *testing.T) {
+	td := t.TempDir()
+	write(t, td, "a.md", "---\ntitle: Alpha\ndate: 2025-08-01\n---\nalpha\n")
+	write(t, td, "next.md", "---\ntitle: Next\ndate: 2025-09-01\n---\nAfter [[alpha]].\n")
+	write(t, td, "last.md", "---\ntitle: Last\ndate: 2025-09-10\n---\nAfter [[next]].\n")
+
+	now := time.Date(2025, 8, 15, 0, 0, 0, 0, time.UTC)
+	s, err := NewFilesStore(td, WithNow(func() time.Time { return now }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g := s.Graph(); len(g.Edges) != 0 {
+		t.Fatalf("edges from scheduled posts: %+v", g.Edges)
+	}
+
+	now = time.Date(2025, 9, 11, 0, 0, 0, 0, time.UTC)
+	if p, _ := s.BySlug("next"); len(p.Backlinks) != 1 || p.Backlinks[0].Slug != "last" {
+		t.Fatalf("next backlinks after going live = %+v", p.Backlinks)
+	}
+	if p, _ := s.BySlug("alpha"); len(p.Backlinks) != 1 || p.Backlinks[0].Slug != "next" {
+		t.Fatalf("alpha backlinks after going live = %+v", p.Backlinks)
+	}
+}
+
+func TestWikiLinks_UnpublishedTargetFailsLoad(t *testing.T) {
+	now := time.Date(2025, 8, 15, 0, 0, 0, 0, time.UTC)
+	cases := map[string]struct{ target, want string }{
+		"draft":     {"---\ntitle: Target\ndraft: true\n---\n", "wiki link a.md: [[target]] is target.md, which is not published while this post is"},
+		"scheduled": {"---\ntitle: Target\ndate: 2025-09-01\n---\n", "wiki link a.md: [[target]] is target.md, which is not published while this post is"},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			td := t.TempDir()
+			write(t, td, "a.md", "---\ntitle: Alpha\ndate: 2025-08-01\n---\nSee [[target]].\n")
+			write(t, td, "target.md", c.target)
+			if _, err := NewFilesStore(td, WithNow(func() time.Time { return now })); err == nil || err.Error() != c.want {
+				t.Fatalf("err=%v, want %q", err, c.want)
+			}
+			// With drafts shown (dev) every post is live, so the link is fine.
+			if _, err := NewFilesStore(td, WithDrafts(true), WithNow(func() time.Time { return now })); err != nil {
+				t.Fatalf("with drafts: %v", err)
+			}
+		})
+	}
+}
+
+func TestWikiLinks_UnresolvedFailsLoad(t *testing.T) {
+	cases := map[string]struct{ body, want string }{
+		"missing":   {"[[nope]]", `wiki link bad.md: [[nope]] matches no post slug or title`},
+		"ambiguous": {"[[Twin]]", `wiki link bad.md: [[Twin]] matches 2 posts by title; link by slug instead`},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			td := t.TempDir()
+			write(t, td, "bad.md", "---\ntitle: Bad\n---\n"+c.body+"\n")
+			write(t, td, "t1.md", "---\ntitle: Twin\nslug: twin-1\n---\n")
+			write(t, td, "t2.md", "---\ntitle: Twin\nslug: twin-2\n---\n")
+			if _, err := NewFilesStore(td); err == nil || err.Error() != c.want {
+				t.Fatalf("err=%v, want %q", err, c.want)
+			}
+		})
+	}
+}
+
+func TestWikiLinks_InsideShortcodes(t *testing.T) {
+	s, err := shortcodeStore(t, map[string]string{
+		"a.md": "---\ntitle: Alpha\n---\n{{< note >}}\nSee [[beta]].\n{{< /note >}}\n",
+		"b.md": "---\ntitle: Beta\n---\nb\n",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := s.BySlug("alpha")
+	if !strings.Contains(string(a.HTML), `<aside class="note "><p>See <a href="/blog/beta">Beta</a>.</p>`) {
+		t.Fatalf("wiki link in shortcode not resolved:\n%s", a.HTML)
+	}
+	if b, _ := s.BySlug("beta"); len(b.Backlinks) != 1 || b.Backlinks[0].Slug != "alpha" {
+		t.Fatalf("beta backlinks = %+v", b.Backlinks)
+	}
+
+	_, err = shortcodeStore(t, map[string]string{
+		"bad.md": "---\ntitle: Bad\n---\n{{< note >}}[[nope]]{{< /note >}}\n",
+	})
+	if want := "wiki link bad.md: [[nope]] matches no post slug or title"; err == nil || err.Error() != want {
+		t.Fatalf("err=%v, want %q", err, want)
+	}
+}
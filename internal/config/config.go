@@ -15,6 +15,8 @@ type Preload struct {
 	As   string `json:"As"`
 }
 
+// Head lists what every page loads. URLs under /static/ are rewritten to
+// their content-hashed form by the asset template func.
 type Head struct {
 	MetaDescription string    `json:"MetaDescription"`
 	Preloads        []Preload `json:"Preloads"`
@@ -64,6 +66,19 @@ type Project struct {
 	Thumb     string        `json:"Thumb,omitempty"`
 	Images    []string      `json:"Images,omitempty"`
 	Highlights []string     `json:"Highlights,omitempty"`
+
+	// Thumb and Images with their resized variants, filled in at startup;
+	// not read from JSON. The project cards show only the Gallery.
+	Thumbnail *Picture  `json:"-"`
+	Gallery   []Picture `json:"-"`
+}
+
+// Picture is an image ready for a responsive <img>.
+type Picture struct {
+	Src    string
+	Srcset string // "" when the image has no resized variants
+	Width  int    // intrinsic size; 0 when unknown (e.g. external images)
+	Height int
 }
 
 /* ---------- Legacy Work schema (auto-mapped) ---------- */
@@ -204,6 +219,71 @@ type Footer struct {
 	Note string `json:"Note"`
 }
 
+// Robots configures /robots.txt in production. Non-prod environments
+// always disallow everything.
+type Robots struct {
+	Disallow []string `json:"Disallow,omitempty"` // extra paths to keep crawlers out of
+	Allow    []string `json:"Allow,omitempty"`
+}
+
+// Blog holds blog presentation settings.
+type Blog struct {
+	PageSize int      `json:"PageSize,omitempty"` // posts per index page; 0 means DefaultPageSize
+	Syntax   Syntax   `json:"Syntax"`
+	Markdown Markdown `json:"Markdown"`
+	TOC      TOC      `json:"TOC"`
+	Authors  []Author `json:"Authors,omitempty"` // who posts may credit with `authors: [ID]`
+}
+
+// Author is a blog author, referenced from post front matter by ID.
+type Author struct {
+	ID     string       `json:"ID"`
+	Name   string       `json:"Name"`
+	Avatar string       `json:"Avatar,omitempty"` // image URL
+	Links  []AuthorLink `json:"Links,omitempty"`
+}
+
+type AuthorLink struct {
+	Href  string `json:"Href"`
+	Label string `json:"Label"`
+}
+
+// TOC sets which heading levels appear in a post's table of contents.
+// Zero values fall back to h2..h3.
+type TOC struct {
+	MinDepth int `json:"MinDepth,omitempty"`
+	MaxDepth int `json:"MaxDepth,omitempty"`
+}
+
+// Markdown configures the blog's Markdown renderer.
+type Markdown struct {
+	// Extensions by name (table, strikethrough, tasklist, linkify, footnote,
+	// definitionlist, typographer, highlight, anchors, math, diagrams,
+	// wikilinks). Omitted means all of them; an empty list means plain
+	// CommonMark.
+	Extensions []string `json:"Extensions,omitempty"`
+	Unsafe     bool     `json:"Unsafe,omitempty"`    // pass raw HTML in posts through
+	HardWraps  bool     `json:"HardWraps,omitempty"` // newline in a paragraph -> <br>
+}
+
+// Syntax selects the chroma palettes for highlighted code blocks
+// (https://xyproto.github.io/splash/docs/). Empty means the built-in default.
+type Syntax struct {
+	Light string `json:"Light,omitempty"`
+	Dark  string `json:"Dark,omitempty"`
+}
+
+// DefaultPageSize is used when Blog.PageSize is unset.
+const DefaultPageSize = 10
+
+// PerPage returns the configured page size or DefaultPageSize.
+func (b Blog) PerPage() int {
+	if b.PageSize > 0 {
+		return b.PageSize
+	}
+	return DefaultPageSize
+}
+
 type Config struct {
 	Title   string `json:"Title"`
 	Name    string `json:"Name"`
@@ -221,6 +301,8 @@ type Config struct {
 	Contact  Contact  `json:"Contact"`
 	Footer   Footer   `json:"Footer"`
 	Bookshelf Bookshelf `json:"Bookshelf"`
+	Blog     Blog     `json:"Blog"`
+	Robots   Robots   `json:"Robots"`
 }
 
 
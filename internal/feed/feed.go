@@ -0,0 +1,311 @@
+// internal/feed/feed.go
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+/*
+Syndication feeds for the blog.
+
+A Feed is built once from blog posts and written in any of three formats:
+- Atom 1.0      (RFC 4287)
+- RSS 2.0
+- JSON Feed 1.1 (https://jsonfeed.org/version/1.1)
+
+All links are expected to be absolute; callers join them with the site's BaseURL.
+*/
+
+const (
+	AtomContentType = "application/atom+xml; charset=utf-8"
+	RSSContentType  = "application/rss+xml; charset=utf-8"
+	JSONContentType = "application/feed+json; charset=utf-8"
+)
+
+type Feed struct {
+	Title       string
+	Description string
+	Link        string // absolute URL of the HTML page the feed mirrors
+	FeedURL     string // absolute URL of the feed itself
+	Author      Author
+	Updated     time.Time
+	Items       []Item
+}
+
+type Author struct {
+	Name  string
+	Email string
+	URL   string
+}
+
+type Item struct {
+	ID        string // stable id; the permalink is fine
+	Title     string
+	Link      string
+	Summary   string // plain text
+	Content   string // HTML
+	Published time.Time
+	Updated   time.Time // falls back to Published, then Feed.Updated
+	Tags      []string
+}
+
+func (it Item) updated(f Feed) time.Time {
+	switch {
+	case !it.Updated.IsZero():
+		return it.Updated
+	case !it.Published.IsZero():
+		return it.Published
+	}
+	return f.Updated // undated
+}
+
+/************ Atom ************/
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   *atomAuthor `xml:"author,omitempty"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name  string `xml:"name"`
+	Email string `xml:"email,omitempty"`
+	URI   string `xml:"uri,omitempty"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr,omitempty"`
+	Body string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Links      []atomLink     `xml:"link"`
+	Published  string         `xml:"published,omitempty"`
+	Updated    string         `xml:"updated"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+}
+
+// WriteAtom writes f as an Atom 1.0 document.
+func WriteAtom(w io.Writer, f Feed) error {
+	af := atomFeed{
+		Title:    f.Title,
+		Subtitle: f.Description,
+		ID:       f.FeedURL,
+		Updated:  atomTime(f.Updated),
+		Links: []atomLink{
+			{Href: f.Link, Rel: "alternate", Type: "text/html"},
+			{Href: f.FeedURL, Rel: "self", Type: "application/atom+xml"},
+		},
+	}
+	if f.Author.Name != "" {
+		af.Author = &atomAuthor{Name: f.Author.Name, Email: f.Author.Email, URI: f.Author.URL}
+	}
+	for _, it := range f.Items {
+		e := atomEntry{
+			Title:   it.Title,
+			ID:      it.ID,
+			Links:   []atomLink{{Href: it.Link, Rel: "alternate", Type: "text/html"}},
+			Updated: atomTime(it.updated(f)),
+		}
+		if !it.Published.IsZero() {
+			e.Published = atomTime(it.Published)
+		}
+		for _, t := range it.Tags {
+			e.Categories = append(e.Categories, atomCategory{Term: t})
+		}
+		if it.Summary != "" {
+			e.Summary = &atomText{Type: "text", Body: it.Summary}
+		}
+		if it.Content != "" {
+			e.Content = &atomText{Type: "html", Body: it.Content}
+		}
+		af.Entries = append(af.Entries, e)
+	}
+	return writeXML(w, af)
+}
+
+func atomTime(t time.Time) string { return t.UTC().Format(time.RFC3339) }
+
+/************ RSS 2.0 ************/
+
+type rssFeed struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	Description   string     `xml:"description"`
+	AtomLink      atomLinkNS `xml:"atom:link"`
+	LastBuildDate string     `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem  `xml:"item"`
+}
+
+// atomLinkNS is the <atom:link rel="self"> RSS readers use to find the feed URL.
+type atomLinkNS struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate,omitempty"`
+	Description string   `xml:"description,omitempty"`
+	Content     *cdata   `xml:"content:encoded,omitempty"`
+	Categories  []string `xml:"category"`
+}
+
+type cdata struct {
+	Body string `xml:",cdata"`
+}
+
+// WriteRSS writes f as an RSS 2.0 document. Item content goes in
+// content:encoded; the summary is the description.
+func WriteRSS(w io.Writer, f Feed) error {
+	ch := rssChannel{
+		Title:       f.Title,
+		Link:        f.Link,
+		Description: f.Description,
+		AtomLink:    atomLinkNS{Href: f.FeedURL, Rel: "self", Type: "application/rss+xml"},
+	}
+	if !f.Updated.IsZero() {
+		ch.LastBuildDate = f.Updated.UTC().Format(time.RFC1123Z)
+	}
+	if ch.Description == "" {
+		ch.Description = f.Title
+	}
+	for _, it := range f.Items {
+		ri := rssItem{
+			Title:       it.Title,
+			Link:        it.Link,
+			GUID:        rssGUID{IsPermaLink: it.ID == it.Link, Value: it.ID},
+			Description: it.Summary,
+			Categories:  it.Tags,
+		}
+		if !it.Published.IsZero() {
+			ri.PubDate = it.Published.UTC().Format(time.RFC1123Z)
+		}
+		if it.Content != "" {
+			ri.Content = &cdata{Body: it.Content}
+		}
+		ch.Items = append(ch.Items, ri)
+	}
+	return writeXML(w, rssFeed{
+		Version:   "2.0",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		Channel:   ch,
+	})
+}
+
+/************ JSON Feed 1.1 ************/
+
+type jsonFeed struct {
+	Version     string       `json:"version"`
+	Title       string       `json:"title"`
+	HomePageURL string       `json:"home_page_url,omitempty"`
+	FeedURL     string       `json:"feed_url,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Authors     []jsonAuthor `json:"authors,omitempty"`
+	Items       []jsonItem   `json:"items"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+type jsonItem struct {
+	ID            string   `json:"id"`
+	URL           string   `json:"url,omitempty"`
+	Title         string   `json:"title,omitempty"`
+	ContentHTML   string   `json:"content_html,omitempty"`
+	Summary       string   `json:"summary,omitempty"`
+	DatePublished string   `json:"date_published,omitempty"`
+	DateModified  string   `json:"date_modified,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+}
+
+// WriteJSON writes f as a JSON Feed 1.1 document.
+func WriteJSON(w io.Writer, f Feed) error {
+	jf := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.FeedURL,
+		Description: f.Description,
+		Items:       []jsonItem{},
+	}
+	if f.Author.Name != "" {
+		jf.Authors = []jsonAuthor{{Name: f.Author.Name, URL: f.Author.URL}}
+	}
+	for _, it := range f.Items {
+		ji := jsonItem{
+			ID:          it.ID,
+			URL:         it.Link,
+			Title:       it.Title,
+			ContentHTML: it.Content,
+			Summary:     it.Summary,
+			Tags:        it.Tags,
+		}
+		if !it.Published.IsZero() {
+			ji.DatePublished = it.Published.UTC().Format(time.RFC3339)
+		}
+		if !it.Updated.IsZero() {
+			ji.DateModified = it.Updated.UTC().Format(time.RFC3339)
+		}
+		jf.Items = append(jf.Items, ji)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(jf)
+}
+
+/************ helpers ************/
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,103 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sample() Feed {
+	d := time.Date(2025, 8, 2, 0, 0, 0, 0, time.UTC)
+	return Feed{
+		Title:   "Site",
+		Link:    "https://example.com/blog",
+		FeedURL: "https://example.com/blog/feed.atom",
+		Author:  Author{Name: "Elliot"},
+		Updated: d,
+		Items: []Item{{
+			ID:        "https://example.com/blog/hello",
+			Title:     "Hello & welcome",
+			Link:      "https://example.com/blog/hello",
+			Summary:   "Short",
+			Content:   "<p>Hi</p>",
+			Published: d,
+			Tags:      []string{"go"},
+		}},
+	}
+}
+
+func TestWriteAtom(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteAtom(&buf, sample()); err != nil {
+		t.Fatal(err)
+	}
+	var got atomFeed
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid xml: %v\n%s", err, buf.String())
+	}
+	if len(got.Entries) != 1 {
+		t.Fatalf("entries=%d, want 1", len(got.Entries))
+	}
+	e := got.Entries[0]
+	if e.Title != "Hello & welcome" || e.Content == nil || e.Content.Body != "<p>Hi</p>" {
+		t.Fatalf("entry = %+v", e)
+	}
+	if e.Updated != "2025-08-02T00:00:00Z" {
+		t.Fatalf("updated = %q", e.Updated)
+	}
+
+	// Atom requires <updated>; an undated item takes the feed's.
+	f := sample()
+	f.Items[0].Published = time.Time{}
+	f.Updated = time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	buf.Reset()
+	if err := WriteAtom(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+	var undated atomFeed
+	if err := xml.Unmarshal(buf.Bytes(), &undated); err != nil {
+		t.Fatal(err)
+	}
+	if u := undated.Entries[0].Updated; u != "2025-09-01T00:00:00Z" {
+		t.Fatalf("undated entry updated = %q, want the feed's", u)
+	}
+}
+
+func TestWriteRSS(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteRSS(&buf, sample()); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`<rss version="2.0"`,
+		`<guid isPermaLink="true">https://example.com/blog/hello</guid>`,
+		`<pubDate>Sat, 02 Aug 2025 00:00:00 +0000</pubDate>`,
+		`<content:encoded><![CDATA[<p>Hi</p>]]></content:encoded>`,
+		`<description>Short</description>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("rss missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, sample()); err != nil {
+		t.Fatal(err)
+	}
+	var got jsonFeed
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != "https://jsonfeed.org/version/1.1" {
+		t.Fatalf("version = %q", got.Version)
+	}
+	if len(got.Items) != 1 || got.Items[0].ContentHTML != "<p>Hi</p>" || got.Items[0].DatePublished != "2025-08-02T00:00:00Z" {
+		t.Fatalf("items = %+v", got.Items)
+	}
+}
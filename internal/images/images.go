@@ -0,0 +1,355 @@
+// internal/images/images.go
+package images
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"image"
+	_ "image/gif" // dimensions only; see encoders
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // dimensions only; see encoders
+)
+
+/*
+Responsive images: resized variants of site images for srcset.
+
+A Processor reads a source image, writes narrower copies at the configured
+widths into its cache directory and describes the result as an Image: the
+original, its variants and the intrinsic size for width/height attributes,
+so the page doesn't shift as images load. Variants are named by the source
+file and a hash of its bytes, so an edited image gets new URLs and the old
+ones can be cached forever:
+
+	/static/_img/map-3f2a9c1e7b4d-640.png
+
+Existing variants are reused, so only new or changed images cost a resize.
+The cache directory is disposable; deleting it prunes variants of images
+that are gone.
+
+JPEG and PNG sources get variants in their own format. GIF and WebP sources
+get dimensions only: GIFs are often animated, and Go has no pure WebP
+encoder (x/image/webp only decodes), so no variant is ever WebP. Formats Go
+can't decode, such as SVG, are left alone.
+*/
+
+// DefaultWidths are the variant widths used when WithWidths is not given.
+var DefaultWidths = []int{320, 640, 1024, 1600}
+
+// DefaultQuality is the JPEG quality used when WithQuality is not given.
+const DefaultQuality = 82
+
+// Image is a source image and its resized variants.
+type Image struct {
+	Src      string // URL of the original
+	Width    int    // intrinsic size of the original, in pixels
+	Height   int
+	Variants []Variant // narrower copies, narrowest first
+}
+
+// Variant is a resized copy of an Image.
+type Variant struct {
+	URL    string
+	Width  int
+	Height int
+}
+
+// Srcset returns the variants and the original with their widths, for a
+// srcset attribute; "" when there are no variants.
+func (im Image) Srcset() string {
+	if len(im.Variants) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(im.Variants)+1)
+	for _, v := range im.Variants {
+		parts = append(parts, v.URL+" "+strconv.Itoa(v.Width)+"w")
+	}
+	parts = append(parts, im.Src+" "+strconv.Itoa(im.Width)+"w")
+	return strings.Join(parts, ", ")
+}
+
+// encoder writes variants of a decoded format.
+type encoder struct {
+	ext    string
+	encode func(w io.Writer, m image.Image, quality int) error
+}
+
+var encoders = map[string]encoder{
+	"jpeg": {"jpg", func(w io.Writer, m image.Image, q int) error {
+		return jpeg.Encode(w, m, &jpeg.Options{Quality: q})
+	}},
+	"png": {"png", func(w io.Writer, m image.Image, _ int) error { return png.Encode(w, m) }},
+}
+
+// Processor makes and describes resized variants.
+type Processor struct {
+	dir     string // cache directory holding the variants
+	prefix  string // URL path dir is served at, with a trailing slash
+	widths  []int  // ascending
+	quality int
+	sizes   string
+	sources []source
+}
+
+// source maps a URL path prefix to the files served there.
+type source struct {
+	prefix string
+	fsys   fs.FS
+}
+
+// Functional options
+type Option func(*Processor)
+
+// WithWidths sets the variant widths. An image gets a variant for each
+// width narrower than itself.
+func WithWidths(widths ...int) Option {
+	return func(p *Processor) { p.widths = append([]int(nil), widths...) }
+}
+
+// WithQuality sets the JPEG quality (1-100) of variants.
+func WithQuality(q int) Option { return func(p *Processor) { p.quality = q } }
+
+// WithSizes sets the sizes attribute RewriteHTML gives images with variants.
+func WithSizes(sizes string) Option { return func(p *Processor) { p.sizes = sizes } }
+
+// WithSource lets Resolve find images under the URL path prefix in fsys,
+// e.g. "/static/" and the static directory.
+func WithSource(prefix string, fsys fs.FS) Option {
+	return func(p *Processor) { p.sources = append(p.sources, source{prefix, fsys}) }
+}
+
+// New returns a Processor that writes variants to dir, to be served at the
+// URL path prefix. dir is created if needed.
+func New(dir, prefix string, opts ...Option) (*Processor, error) {
+	p := &Processor{
+		dir:     dir,
+		prefix:  strings.TrimSuffix(prefix, "/") + "/",
+		widths:  DefaultWidths,
+		quality: DefaultQuality,
+		sizes:   "100vw",
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.quality < 1 || p.quality > 100 {
+		p.quality = DefaultQuality
+	}
+	widths := p.widths[:0:0]
+	for _, w := range p.widths {
+		if w > 0 {
+			widths = append(widths, w)
+		}
+	}
+	sort.Ints(widths)
+	p.widths = widths
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("images: %w", err)
+	}
+	return p, nil
+}
+
+// Dir returns the cache directory, to serve at the prefix given to New.
+func (p *Processor) Dir() string { return p.dir }
+
+// Resolve processes the image at a site URL such as "/static/img/a.png".
+// ok is false when the URL is not under a source, names no file or is not
+// an image Go can decode; such images are best left as they are.
+func (p *Processor) Resolve(src string) (im Image, ok bool, err error) {
+	u, err := url.Parse(src)
+	if err != nil || u.Scheme != "" || u.Host != "" {
+		return Image{}, false, nil
+	}
+	for _, s := range p.sources {
+		if name, found := strings.CutPrefix(u.Path, s.prefix); found {
+			return p.Process(s.fsys, name, src)
+		}
+	}
+	return Image{}, false, nil
+}
+
+// Process makes the variants of the image name in fsys, which is served at
+// the URL src. ok is false when there is no such file or Go can't decode
+// it; a file that claims a known format but is broken is an error.
+func (p *Processor) Process(fsys fs.FS, name, src string) (im Image, ok bool, err error) {
+	b, err := fs.ReadFile(fsys, name)
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrInvalid) {
+		return Image{}, false, nil
+	}
+	if err != nil {
+		return Image{}, false, fmt.Errorf("image %s: %w", name, err)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(b))
+	if errors.Is(err, image.ErrFormat) {
+		return Image{}, false, nil
+	}
+	if err != nil {
+		return Image{}, false, fmt.Errorf("image %s: %w", name, err)
+	}
+	im = Image{Src: src, Width: cfg.Width, Height: cfg.Height}
+	enc, ok := encoders[format]
+	if !ok || cfg.Width == 0 {
+		return im, true, nil
+	}
+
+	sum := sha256.Sum256(b)
+	stem := fileStem(name) + "-" + hex.EncodeToString(sum[:6])
+	var decoded image.Image
+	for _, w := range p.widths {
+		if w >= cfg.Width {
+			break
+		}
+		h := max(1, (cfg.Height*w+cfg.Width/2)/cfg.Width)
+		file := stem + "-" + strconv.Itoa(w) + "." + enc.ext
+		if _, err := os.Stat(filepath.Join(p.dir, file)); errors.Is(err, fs.ErrNotExist) {
+			if decoded == nil {
+				if decoded, _, err = image.Decode(bytes.NewReader(b)); err != nil {
+					return Image{}, false, fmt.Errorf("image %s: %w", name, err)
+				}
+			}
+			if err := p.write(file, resize(decoded, w, h), enc); err != nil {
+				return Image{}, false, fmt.Errorf("image %s: %w", name, err)
+			}
+		} else if err != nil {
+			return Image{}, false, fmt.Errorf("image %s: %w", name, err)
+		}
+		im.Variants = append(im.Variants, Variant{URL: p.prefix + file, Width: w, Height: h})
+	}
+	return im, true, nil
+}
+
+// resize scales m to w x h.
+func resize(m image.Image, w, h int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), m, m.Bounds(), draw.Src, nil)
+	return dst
+}
+
+// write encodes m into the cache as file. It goes through a temporary file
+// so a variant is never served half-written.
+func (p *Processor) write(file string, m image.Image, enc encoder) error {
+	f, err := os.CreateTemp(p.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // no-op once renamed
+	err = enc.encode(f, m, p.quality)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filepath.Join(p.dir, file))
+}
+
+// fileStem returns a URL-safe form of the file's base name, without its
+// extension.
+func fileStem(name string) string {
+	base := strings.TrimSuffix(path.Base(name), path.Ext(name))
+	var b strings.Builder
+	for _, r := range strings.ToLower(base) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteByte('-')
+		}
+	}
+	if s := strings.TrimSuffix(b.String(), "-"); s != "" {
+		return s
+	}
+	return "img"
+}
+
+/************ HTML ************/
+
+var (
+	imgTagRE  = regexp.MustCompile(`(?i)<img\s[^>]*>`)
+	imgAttrRE = regexp.MustCompile(`\s([a-zA-Z][-a-zA-Z0-9_:]*)(?:\s*=\s*("[^"]*"|'[^']*'|[^\s"'>]+))?`)
+)
+
+// RewriteHTML adds width and height, and srcset and sizes when there are
+// variants, to the <img> tags of page. base and assets are the page's own
+// URL path and files, for images next to it (a blog page bundle); assets
+// may be nil. Other images are found through Resolve. Attributes already
+// present are kept, and images that can't be resolved are left alone.
+func (p *Processor) RewriteHTML(page []byte, base string, assets fs.FS) ([]byte, error) {
+	var firstErr error
+	out := imgTagRE.ReplaceAllFunc(page, func(tag []byte) []byte {
+		if firstErr != nil {
+			return tag
+		}
+		attrs := make(map[string]string)
+		for _, m := range imgAttrRE.FindAllSubmatch(tag[len("<img"):], -1) {
+			attrs[strings.ToLower(string(m[1]))] = unquoteAttr(string(m[2]))
+		}
+		src, ok := attrs["src"]
+		if !ok {
+			return tag
+		}
+		im, ok, err := p.resolvePage(src, base, assets)
+		if err != nil {
+			firstErr = err
+			return tag
+		}
+		if !ok {
+			return tag
+		}
+
+		var add strings.Builder
+		_, hasW := attrs["width"]
+		_, hasH := attrs["height"]
+		if !hasW && !hasH && im.Width > 0 {
+			fmt.Fprintf(&add, ` width="%d" height="%d"`, im.Width, im.Height)
+		}
+		if _, has := attrs["srcset"]; !has && len(im.Variants) > 0 {
+			fmt.Fprintf(&add, ` srcset="%s" sizes="%s"`, html.EscapeString(im.Srcset()), html.EscapeString(p.sizes))
+		}
+		if add.Len() == 0 {
+			return tag
+		}
+		end := len(tag) - 1
+		for end > 0 && (tag[end-1] == '/' || tag[end-1] == ' ') {
+			end--
+		}
+		return []byte(string(tag[:end]) + add.String() + string(tag[end:]))
+	})
+	return out, firstErr
+}
+
+// resolvePage resolves src against the page's own files first.
+func (p *Processor) resolvePage(src, base string, assets fs.FS) (Image, bool, error) {
+	if assets != nil && base != "" {
+		if u, err := url.Parse(src); err == nil && u.Scheme == "" && u.Host == "" {
+			if name, found := strings.CutPrefix(u.Path, base); found {
+				return p.Process(assets, name, src)
+			}
+		}
+	}
+	return p.Resolve(src)
+}
+
+// unquoteAttr returns an attribute value without quotes or character
+// references.
+func unquoteAttr(v string) string {
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') {
+		v = v[1 : len(v)-1]
+	}
+	return html.UnescapeString(v)
+}
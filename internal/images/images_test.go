@@ -0,0 +1,144 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func pngBytes(t *testing.T, w, h int) []byte {
+	t.Helper()
+	m := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		m.Set(x, 0, color.NRGBA{R: uint8(x), A: 255})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func jpegBytes(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, w, h)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcess_WritesNarrowerVariants(t *testing.T) {
+	dir := t.TempDir()
+	p, err := New(dir, "/static/_img", WithWidths(1000, 200, 400))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{"photos/My Photo.jpg": {Data: jpegBytes(t, 800, 600)}}
+
+	im, ok, err := p.Process(fsys, "photos/My Photo.jpg", "/static/photos/My%20Photo.jpg")
+	if err != nil || !ok {
+		t.Fatalf("ok=%v err=%v", ok, err)
+	}
+	if im.Width != 800 || im.Height != 600 || len(im.Variants) != 2 {
+		t.Fatalf("image = %+v, want 800x600 with 200 and 400 wide variants", im)
+	}
+	v := im.Variants[1]
+	if v.Width != 400 || v.Height != 300 || !strings.HasPrefix(v.URL, "/static/_img/my-photo-") || !strings.HasSuffix(v.URL, "-400.jpg") {
+		t.Fatalf("variant = %+v", v)
+	}
+	f, err := os.Open(filepath.Join(dir, strings.TrimPrefix(v.URL, "/static/_img/")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, format, err := image.DecodeConfig(f)
+	f.Close()
+	if err != nil || format != "jpeg" || cfg.Width != 400 || cfg.Height != 300 {
+		t.Fatalf("variant file: %s %dx%d, %v", format, cfg.Width, cfg.Height, err)
+	}
+	want := im.Variants[0].URL + " 200w, " + v.URL + " 400w, /static/photos/My%20Photo.jpg 800w"
+	if got := im.Srcset(); got != want {
+		t.Fatalf("srcset = %q, want %q", got, want)
+	}
+
+	// Existing variants are reused rather than encoded again.
+	old := time.Now().Add(-time.Hour)
+	path := filepath.Join(dir, strings.TrimPrefix(v.URL, "/static/_img/"))
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := p.Process(fsys, "photos/My Photo.jpg", "/x.jpg"); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(path); !info.ModTime().Equal(old) {
+		t.Fatal("variant was written again")
+	}
+}
+
+func TestProcess_SkipsWhatItCannotResize(t *testing.T) {
+	p, err := New(t.TempDir(), "/v/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{
+		"small.png": {Data: pngBytes(t, 100, 50)},
+		"logo.svg":  {Data: []byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`)},
+		"bad.png":   {Data: []byte("\x89PNG\r\n\x1a\nnope")},
+	}
+
+	im, ok, err := p.Process(fsys, "small.png", "/small.png")
+	if err != nil || !ok || im.Width != 100 || len(im.Variants) != 0 || im.Srcset() != "" {
+		t.Fatalf("small: %+v ok=%v err=%v", im, ok, err)
+	}
+	for _, name := range []string{"logo.svg", "missing.png"} {
+		if _, ok, err := p.Process(fsys, name, "/"+name); ok || err != nil {
+			t.Fatalf("%s: ok=%v err=%v, want skipped", name, ok, err)
+		}
+	}
+	if _, _, err := p.Process(fsys, "bad.png", "/bad.png"); err == nil || !strings.Contains(err.Error(), "image bad.png") {
+		t.Fatalf("bad.png: err=%v", err)
+	}
+}
+
+func TestRewriteHTML(t *testing.T) {
+	p, err := New(t.TempDir(), "/static/_img/",
+		WithWidths(320),
+		WithSizes("50vw"),
+		WithSource("/static/", fstest.MapFS{"img/wide.png": {Data: pngBytes(t, 640, 320)}}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := fstest.MapFS{"map.png": {Data: pngBytes(t, 200, 100)}}
+	page := `<p><img src="/static/img/wide.png" alt="Wide &amp; short"></p>
+<p><img src="/blog/trip/map.png" alt="map" /></p>
+<img src="https://example.com/a.png" alt="external">
+<img src="/static/img/wide.png" width="10" srcset="x.png 1x">
+<pre><code>&lt;img src="/static/img/wide.png"&gt;</code></pre>`
+
+	out, err := p.RewriteHTML([]byte(page), "/blog/trip/", bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(out)
+	for _, want := range []string{
+		`<img src="/static/img/wide.png" alt="Wide &amp; short" width="640" height="320" srcset="/static/_img/wide-`,
+		`-320.png 320w, /static/img/wide.png 640w" sizes="50vw">`,
+		`<img src="/blog/trip/map.png" alt="map" width="200" height="100" />`,
+		`<img src="https://example.com/a.png" alt="external">`,
+		`<img src="/static/img/wide.png" width="10" srcset="x.png 1x">`,
+		`&lt;img src="/static/img/wide.png"&gt;`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("missing %q in:\n%s", want, got)
+		}
+	}
+}
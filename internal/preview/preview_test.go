@@ -0,0 +1,39 @@
+package preview
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSigner_RoundTrip(t *testing.T) {
+	now := time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)
+	s := NewSigner([]byte("secret"))
+	s.now = func() time.Time { return now }
+
+	tok := s.Token("draft-post", time.Hour)
+	exp, err := s.Verify("draft-post", tok)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !exp.Equal(now.Add(time.Hour)) {
+		t.Fatalf("expiry = %v, want %v", exp, now.Add(time.Hour))
+	}
+
+	if _, err := s.Verify("other-post", tok); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("other slug: err = %v, want ErrInvalid", err)
+	}
+	if _, err := NewSigner([]byte("rotated")).Verify("draft-post", tok); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("other secret: err = %v, want ErrInvalid", err)
+	}
+	for _, bad := range []string{"", "nodot", "zz.!!!", tok + "x", "1" + tok} {
+		if _, err := s.Verify("draft-post", bad); !errors.Is(err, ErrInvalid) {
+			t.Fatalf("token %q: err = %v, want ErrInvalid", bad, err)
+		}
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := s.Verify("draft-post", tok); !errors.Is(err, ErrExpired) {
+		t.Fatalf("at expiry: err = %v, want ErrExpired", err)
+	}
+}
@@ -0,0 +1,85 @@
+// web/static/js/modules/search.js
+
+/**
+ * Instant blog search.
+ * - Upgrades any form[data-instant-search] to query /blog/search as JSON while typing.
+ * - Renders hits into the element named by the attribute (created after the form if missing).
+ * - Without JS the form still submits to the HTML search page.
+ */
+export function initSearch() {
+  document.querySelectorAll("form[data-instant-search]").forEach((form) => {
+    const input = form.querySelector("input[name='q']");
+    if (!input) return;
+
+    let out = document.querySelector(form.dataset.instantSearch);
+    if (!out) {
+      out = document.createElement("div");
+      out.className = "grid search-results";
+      out.setAttribute("aria-live", "polite");
+      form.after(out);
+    }
+
+    let timer = 0;
+    let ctrl = null;
+
+    const render = (results, q) => {
+      out.replaceChildren();
+      if (!q) return;
+      if (!results.length) {
+        const empty = document.createElement("div");
+        empty.className = "card";
+        empty.textContent = `No posts match “${q}”.`;
+        out.append(empty);
+        return;
+      }
+      for (const r of results) {
+        const card = document.createElement("article");
+        card.className = "card";
+
+        const h = document.createElement("h3");
+        const a = document.createElement("a");
+        a.href = r.url;
+        a.textContent = r.title;
+        h.append(a);
+
+        const meta = document.createElement("div");
+        meta.className = "meta";
+        meta.textContent = r.date || "";
+
+        const p = document.createElement("p");
+        p.className = "snippet";
+        p.innerHTML = r.snippet; // server-escaped; only <mark> is markup
+
+        card.append(h, meta, p);
+        out.append(card);
+      }
+    };
+
+    const run = async () => {
+      const q = input.value.trim();
+      if (ctrl) ctrl.abort();
+      if (!q) {
+        render([], q);
+        return;
+      }
+      ctrl = new AbortController();
+      try {
+        const res = await fetch(`/blog/search?q=${encodeURIComponent(q)}`, {
+          headers: { Accept: "application/json" },
+          signal: ctrl.signal,
+        });
+        if (!res.ok) return;
+        const data = await res.json();
+        render(data.results || [], q);
+        if (location.pathname === form.getAttribute("action")) {
+          history.replaceState(null, "", `?q=${encodeURIComponent(q)}`);
+        }
+      } catch { /* aborted or offline: keep the current results */ }
+    };
+
+    input.addEventListener("input", () => {
+      clearTimeout(timer);
+      timer = setTimeout(run, 150);
+    });
+  });
+}